@@ -0,0 +1,137 @@
+package easyjson
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+)
+
+// ByteEncoding selects how byte slices are stored in JSON strings by
+// NewJSONBytes and read back by AsBytes.
+type ByteEncoding int
+
+const (
+	// BytesHex is lowercase hexadecimal, the default.
+	BytesHex ByteEncoding = iota
+	// BytesBase64 is standard base64 with padding (RFC 4648 section 4).
+	BytesBase64
+	// BytesBase64URL is URL-safe base64 with padding (RFC 4648 section 5).
+	BytesBase64URL
+	// BytesRawBase64 is standard base64 without padding.
+	BytesRawBase64
+	// BytesRawBase64URL is URL-safe base64 without padding, as used by JWT.
+	BytesRawBase64URL
+	// BytesAuto accepts any of the encodings above when decoding, trying
+	// them in the order listed, so a string that is valid hex is always
+	// read as hex. Encoding with BytesAuto produces hex.
+	BytesAuto
+)
+
+func (e ByteEncoding) String() string {
+	switch e {
+	case BytesHex:
+		return "hex"
+	case BytesBase64:
+		return "base64"
+	case BytesBase64URL:
+		return "base64url"
+	case BytesRawBase64:
+		return "raw base64"
+	case BytesRawBase64URL:
+		return "raw base64url"
+	case BytesAuto:
+		return "auto"
+	}
+	return fmt.Sprintf("ByteEncoding(%d)", int(e))
+}
+
+var defaultByteEncoding int32 // a ByteEncoding, accessed atomically
+
+// SetDefaultByteEncoding sets the encoding used by NewJSONBytes, AsBytes,
+// FromStruct and As for []byte values, and for the byte strings decoded from
+// CBOR, MessagePack and YAML. It is safe for concurrent use, but values
+// written under one default are not readable under another, so it is best
+// set once at program start.
+func SetDefaultByteEncoding(enc ByteEncoding) {
+	atomic.StoreInt32(&defaultByteEncoding, int32(enc))
+}
+
+// DefaultByteEncoding returns the encoding set by SetDefaultByteEncoding.
+func DefaultByteEncoding() ByteEncoding {
+	return ByteEncoding(atomic.LoadInt32(&defaultByteEncoding))
+}
+
+// NewJSONBytesWith creates a new JSON string holding value in encoding enc.
+func NewJSONBytesWith(value []byte, enc ByteEncoding) JSON {
+	return NewJSON(encodeBytes(value, enc))
+}
+
+// AsBytesWith decodes the JSON value as a string of bytes in encoding enc.
+// The returned error wraps ErrNotBytes if the value is not a string or not
+// valid in that encoding.
+func (j JSON) AsBytesWith(enc ByteEncoding) ([]byte, error) {
+	s, ok := j.Value.(string)
+	if !ok {
+		return nil, fmt.Errorf("easyjson: %w: %s is not a string", ErrNotBytes, jvKindName(j.Value))
+	}
+	b, _, err := decodeBytes(s, enc)
+	return b, err
+}
+
+func base64Encoding(enc ByteEncoding) *base64.Encoding {
+	switch enc {
+	case BytesBase64:
+		return base64.StdEncoding
+	case BytesBase64URL:
+		return base64.URLEncoding
+	case BytesRawBase64:
+		return base64.RawStdEncoding
+	case BytesRawBase64URL:
+		return base64.RawURLEncoding
+	}
+	return nil
+}
+
+func encodeBytes(b []byte, enc ByteEncoding) string {
+	if e := base64Encoding(enc); e != nil {
+		return e.EncodeToString(b)
+	}
+	return hex.EncodeToString(b)
+}
+
+// decodeBytes decodes s in encoding enc and returns the encoding that
+// matched, which differs from enc for BytesAuto.
+func decodeBytes(s string, enc ByteEncoding) ([]byte, ByteEncoding, error) {
+	switch enc {
+	case BytesHex:
+		if b, err := hex.DecodeString(s); err == nil {
+			return b, BytesHex, nil
+		}
+	case BytesAuto:
+		for e := BytesHex; e < BytesAuto; e++ {
+			if b, _, err := decodeBytes(s, e); err == nil {
+				return b, e, nil
+			}
+		}
+	default:
+		e := base64Encoding(enc)
+		if e == nil {
+			return nil, enc, fmt.Errorf("easyjson: unknown byte encoding %d", int(enc))
+		}
+		if b, err := e.Strict().DecodeString(s); err == nil {
+			return b, enc, nil
+		}
+	}
+	return nil, enc, fmt.Errorf("easyjson: %w: not valid %s", ErrNotBytes, enc)
+}
+
+// jvBytesOf reports whether s holds bytes in the canonical form of the
+// default byte encoding, that is exactly as NewJSONBytes would write them.
+func jvBytesOf(s string) ([]byte, bool) {
+	b, enc, err := decodeBytes(s, DefaultByteEncoding())
+	if err != nil || encodeBytes(b, enc) != s {
+		return nil, false
+	}
+	return b, true
+}
@@ -0,0 +1,92 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestByteEncodings(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xbf, 0x01}
+	want := map[ByteEncoding]string{
+		BytesHex:          "fbffbf01",
+		BytesBase64:       "+/+/AQ==",
+		BytesBase64URL:    "-_-_AQ==",
+		BytesRawBase64:    "+/+/AQ",
+		BytesRawBase64URL: "-_-_AQ",
+		BytesAuto:         "fbffbf01",
+	}
+	for enc, s := range want {
+		j := NewJSONBytesWith(data, enc)
+		if j.Value != s {
+			t.Errorf("%v: got %v, want %s", enc, j.Value, s)
+		}
+		b, err := j.AsBytesWith(enc)
+		if err != nil || !bytes.Equal(b, data) {
+			t.Errorf("%v: decoded %x, %v", enc, b, err)
+		}
+		if b, err := j.AsBytesWith(BytesAuto); err != nil || !bytes.Equal(b, data) {
+			t.Errorf("%v: auto-detected %x, %v", enc, b, err)
+		}
+	}
+
+	for _, c := range []struct {
+		v   interface{}
+		enc ByteEncoding
+	}{
+		{"+/+/AQ==", BytesBase64URL}, {"-_-_AQ==", BytesBase64}, {"+/+/AQ", BytesBase64},
+		{"+/+/AQ==", BytesRawBase64}, {"abc", BytesHex}, {"AQ=", BytesAuto}, {42.0, BytesHex},
+		{"AR==", BytesBase64},
+	} {
+		if _, err := NewJSON(c.v).AsBytesWith(c.enc); !errors.Is(err, ErrNotBytes) {
+			t.Errorf("%v as %v: expected ErrNotBytes, got %v", c.v, c.enc, err)
+		}
+	}
+	if _, err := NewJSON("00").AsBytesWith(ByteEncoding(99)); err == nil || errors.Is(err, ErrNotBytes) {
+		t.Errorf("unknown encoding: %v", err)
+	}
+	if b, err := NewJSON("cafe").AsBytesWith(BytesAuto); err != nil || hex.EncodeToString(b) != "cafe" {
+		t.Errorf("auto detection must prefer hex: %x %v", b, err)
+	}
+}
+
+func TestDefaultByteEncoding(t *testing.T) {
+	defer SetDefaultByteEncoding(DefaultByteEncoding())
+	SetDefaultByteEncoding(BytesBase64)
+
+	j := NewJSONBytes([]byte("hi"))
+	if j.Value != "aGk=" {
+		t.Fatalf("NewJSONBytes: %v", j.Value)
+	}
+	if b, ok := j.AsBytes(); !ok || string(b) != "hi" {
+		t.Fatalf("AsBytes: %q", b)
+	}
+	if _, ok := NewJSON("68690a").AsBytes(); ok {
+		t.Fatal("hex must not be accepted under a base64 default")
+	}
+	if b, err := As[[]byte](j); err != nil || string(b) != "hi" {
+		t.Fatalf("As[[]byte]: %q %v", b, err)
+	}
+	s, err := FromStruct(struct{ B []byte }{[]byte("hi")})
+	if err != nil || s.GetByPath("B").Value != "aGk=" {
+		t.Fatalf("FromStruct: %v %v", s.Value, err)
+	}
+	c, _ := j.ToCBOR(CBOREncodeOptions{StringsAsBytes: true})
+	if hex.EncodeToString(c) != "426869" {
+		t.Fatalf("CBOR byte string: %x", c)
+	}
+	if back, _ := JSONFromCBOR(c); back.Value != "aGk=" {
+		t.Fatalf("CBOR decode: %v", back.Value)
+	}
+
+	SetDefaultByteEncoding(BytesAuto)
+	if NewJSONBytes([]byte("hi")).Value != "6869" {
+		t.Fatal("BytesAuto must encode as hex")
+	}
+	for _, s := range []string{"6869", "aGk=", "aGk"} {
+		if b, ok := NewJSON(s).AsBytes(); !ok || string(b) != "hi" {
+			t.Errorf("%s: %q", s, b)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ToCanonicalBytes serializes the JSON value according to the JSON
+// Canonicalization Scheme (RFC 8785): no whitespace, object members sorted
+// by the UTF-16 code units of their keys, numbers written as ECMAScript
+// does and strings with minimal escaping. Array order is kept, so the output
+// can be hashed or signed and verified by any other JCS implementation.
+// Numbers are treated as IEEE 754 doubles; NaN, infinities and strings
+// with invalid UTF-8 are rejected.
+func (j JSON) ToCanonicalBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, j.Value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case string:
+		return writeCanonicalString(buf, x)
+	case json.Number:
+		f, err := x.Float64()
+		if err != nil {
+			return err
+		}
+		return writeCanonicalNumber(buf, f)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return utf16Less(keys[a], keys[b]) })
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalString(buf, k); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, x[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case *OrderedObject:
+		return writeCanonical(buf, x.Map())
+	default:
+		if f, ok := numberToFloat64(v); ok {
+			return writeCanonicalNumber(buf, f)
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var generic interface{}
+		if err := dec.Decode(&generic); err != nil {
+			return err
+		}
+		return writeCanonical(buf, generic)
+	}
+	return nil
+}
+
+// utf16Less compares strings by their UTF-16 code units as RFC 8785 requires.
+func utf16Less(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("easyjson: invalid UTF-8 in string %q", s)
+	}
+	const hexDigits = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+func writeCanonicalNumber(buf *bytes.Buffer, f float64) error {
+	b, err := appendES6Number(nil, f)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+// appendES6Number formats f like ECMAScript's Number.prototype.toString.
+func appendES6Number(b []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return b, errors.New("easyjson: NaN and infinity are not valid JSON numbers")
+	}
+	if f == 0 {
+		return append(b, '0'), nil
+	}
+	if f < 0 {
+		b = append(b, '-')
+		f = -f
+	}
+
+	// Shortest round-trip digits d1..dk and the decimal point position n,
+	// so that f = 0.d1..dk * 10^n.
+	e := strconv.AppendFloat(nil, f, 'e', -1, 64)
+	mant, exp, _ := bytes.Cut(e, []byte{'e'})
+	digits := make([]byte, 0, len(mant))
+	for _, c := range mant {
+		if c != '.' {
+			digits = append(digits, c)
+		}
+	}
+	x, _ := strconv.Atoi(string(exp))
+	n := x + 1
+	k := len(digits)
+
+	switch {
+	case k <= n && n <= 21:
+		b = append(b, digits...)
+		b = append(b, bytes.Repeat([]byte{'0'}, n-k)...)
+	case 0 < n && n <= 21:
+		b = append(b, digits[:n]...)
+		b = append(b, '.')
+		b = append(b, digits[n:]...)
+	case -6 < n && n <= 0:
+		b = append(b, '0', '.')
+		b = append(b, bytes.Repeat([]byte{'0'}, -n)...)
+		b = append(b, digits...)
+	default:
+		b = append(b, digits[0])
+		if k > 1 {
+			b = append(b, '.')
+			b = append(b, digits[1:]...)
+		}
+		b = append(b, 'e')
+		if n-1 >= 0 {
+			b = append(b, '+')
+		}
+		b = strconv.AppendInt(b, int64(n-1), 10)
+	}
+	return b, nil
+}
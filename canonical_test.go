@@ -0,0 +1,72 @@
+package easyjson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToCanonicalBytes_RFC8785Example(t *testing.T) {
+	j := mustJSONFromString(t, `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`)
+	got, err := j.ToCanonicalBytes()
+	if err != nil {
+		t.Fatalf("ToCanonicalBytes failed: %v", err)
+	}
+	want := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+	if string(got) != want {
+		t.Fatalf("unexpected canonical form:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestToCanonicalBytes_KeyOrder(t *testing.T) {
+	j := mustJSONFromString(t, `{"\u20ac":1,"\r":2,"\ufb33":3,"1":4,"\ud83d\ude00":5,"\u0080":6,"\u00f6":7,"<a>":[3,1,2]}`)
+	got, err := j.ToCanonicalBytes()
+	if err != nil {
+		t.Fatalf("ToCanonicalBytes failed: %v", err)
+	}
+	want := "{\"\\r\":2,\"1\":4,\"<a>\":[3,1,2],\"\u0080\":6,\"ö\":7,\"€\":1,\"😀\":5,\"\ufb33\":3}"
+	if string(got) != want {
+		t.Fatalf("unexpected key order:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestES6NumberFormatting(t *testing.T) {
+	cases := map[float64]string{
+		0:                      "0",
+		math.Copysign(0, -1):   "0",
+		1:                      "1",
+		-1.5:                   "-1.5",
+		1e21:                   "1e+21",
+		1e20:                   "100000000000000000000",
+		1e-6:                   "0.000001",
+		1e-7:                   "1e-7",
+		9007199254740992:       "9007199254740992",
+		295147905179352830000:  "295147905179352830000",
+		5e-324:                 "5e-324",
+		1.7976931348623157e308: "1.7976931348623157e+308",
+		123456789.125:          "123456789.125",
+	}
+	for f, want := range cases {
+		got, err := appendES6Number(nil, f)
+		if err != nil || string(got) != want {
+			t.Errorf("%v: got %s (%v), want %s", f, got, err, want)
+		}
+	}
+	if _, err := NewJSON(math.NaN()).ToCanonicalBytes(); err == nil {
+		t.Fatalf("NaN must be rejected")
+	}
+}
+
+func TestToCanonicalBytes_GoValues(t *testing.T) {
+	j := NewJSON(map[string]interface{}{"i": int64(10), "f": float32(0.5), "s": []string{"b", "a"}})
+	got, err := j.ToCanonicalBytes()
+	if err != nil || string(got) != `{"f":0.5,"i":10,"s":["b","a"]}` {
+		t.Fatalf("unexpected output %s (%v)", got, err)
+	}
+	if _, err := NewJSON("\xff").ToCanonicalBytes(); err == nil {
+		t.Fatalf("invalid UTF-8 must be rejected")
+	}
+}
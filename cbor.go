@@ -0,0 +1,602 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// CBOR (RFC 8949) support.
+//
+// Byte strings have no JSON counterpart, so they follow the NewJSONBytes
+// convention: JSONFromCBOR decodes them to strings in the default byte
+// encoding that AsBytes turns back into bytes, and ToCBOR encodes []byte
+// values as byte strings. Such encoded strings are written as text strings
+// unless CBOREncodeOptions.StringsAsBytes is set.
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+// CBOR tags for bignums (RFC 8949 section 3.4.3).
+const (
+	cborTagPosBignum = 2
+	cborTagNegBignum = 3
+)
+
+// CBOREncodeOptions configures ToCBOR.
+type CBOREncodeOptions struct {
+	// Deterministic produces the core deterministic encoding of RFC 8949
+	// section 4.2.1, with map keys sorted by their encoded bytes. Numbers are
+	// treated as IEEE 754 doubles, as in ToCanonicalBytes, and integral ones
+	// are encoded as integers, so two values have the same deterministic
+	// encoding exactly when they have the same canonical JSON form. NaN and
+	// infinities are rejected.
+	Deterministic bool
+	// StringsAsBytes encodes strings that hold bytes exactly as NewJSONBytes
+	// writes them (lowercase hex by default) as byte strings.
+	StringsAsBytes bool
+}
+
+// CBORDecodeOptions configures JSONFromCBOR.
+type CBORDecodeOptions struct {
+	// PreserveKeyOrder decodes maps into *OrderedObject values that keep the
+	// key order of the input.
+	PreserveKeyOrder bool
+	// Numbers selects the Go representation of numbers; the default is
+	// float64, as for JSON input.
+	Numbers NumberMode
+}
+
+// ToCBOR encodes the JSON value as CBOR. Integers of Go integer types,
+// *big.Int and integral json.Number values are encoded as CBOR integers
+// (bignums beyond 64 bits), other numbers as the shortest float that holds
+// them exactly.
+func (j JSON) ToCBOR(opts ...CBOREncodeOptions) ([]byte, error) {
+	e := &cborEncoder{}
+	if len(opts) > 0 {
+		e.opts = opts[0]
+	}
+	if err := e.value(j.Value); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+type cborEncoder struct {
+	opts CBOREncodeOptions
+	buf  bytes.Buffer
+}
+
+func (e *cborEncoder) head(major byte, n uint64) {
+	var b [9]byte
+	switch {
+	case n < 24:
+		e.buf.WriteByte(major<<5 | byte(n))
+		return
+	case n <= math.MaxUint8:
+		b[0], b[1] = major<<5|24, byte(n)
+		e.buf.Write(b[:2])
+	case n <= math.MaxUint16:
+		b[0] = major<<5 | 25
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		e.buf.Write(b[:3])
+	case n <= math.MaxUint32:
+		b[0] = major<<5 | 26
+		binary.BigEndian.PutUint32(b[1:], uint32(n))
+		e.buf.Write(b[:5])
+	default:
+		b[0] = major<<5 | 27
+		binary.BigEndian.PutUint64(b[1:], n)
+		e.buf.Write(b[:9])
+	}
+}
+
+func (e *cborEncoder) value(v interface{}) error {
+	if e.opts.Deterministic {
+		if f, ok := numberToFloat64(v); ok {
+			return e.reducedNumber(f)
+		}
+	}
+	switch x := v.(type) {
+	case nil:
+		e.buf.WriteByte(0xf6)
+	case bool:
+		if x {
+			e.buf.WriteByte(0xf5)
+		} else {
+			e.buf.WriteByte(0xf4)
+		}
+	case string:
+		if e.opts.StringsAsBytes {
+			if b, ok := jvBytesOf(x); ok {
+				e.head(cborBytes, uint64(len(b)))
+				e.buf.Write(b)
+				break
+			}
+		}
+		e.head(cborText, uint64(len(x)))
+		e.buf.WriteString(x)
+	case []byte:
+		e.head(cborBytes, uint64(len(x)))
+		e.buf.Write(x)
+	case float64:
+		e.float(x)
+	case float32:
+		e.float(float64(x))
+	case json.Number:
+		if b, ok := new(big.Int).SetString(string(x), 10); ok {
+			e.bigInt(b)
+			break
+		}
+		f, err := x.Float64()
+		if err != nil {
+			return err
+		}
+		e.float(f)
+	case *big.Int:
+		if x == nil {
+			e.buf.WriteByte(0xf6)
+			break
+		}
+		e.bigInt(x)
+	case []interface{}:
+		e.head(cborArray, uint64(len(x)))
+		for _, el := range x {
+			if err := e.value(el); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}, *OrderedObject:
+		return e.object(x)
+	default:
+		if n, _, ok := jvToInt64(v); ok {
+			e.int(n)
+			break
+		}
+		if n, _, ok := jvToUint64(v); ok {
+			e.head(cborUint, n)
+			break
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var generic interface{}
+		if err := dec.Decode(&generic); err != nil {
+			return err
+		}
+		return e.value(generic)
+	}
+	return nil
+}
+
+func (e *cborEncoder) object(v interface{}) error {
+	obj, _ := jvObject(v)
+	keys := jvObjectKeys(v)
+	if e.opts.Deterministic {
+		// Text keys with shortest heads sort by length first, then bytewise.
+		sort.Slice(keys, func(a, b int) bool {
+			if len(keys[a]) != len(keys[b]) {
+				return len(keys[a]) < len(keys[b])
+			}
+			return keys[a] < keys[b]
+		})
+	}
+	e.head(cborMap, uint64(len(keys)))
+	for _, k := range keys {
+		e.head(cborText, uint64(len(k)))
+		e.buf.WriteString(k)
+		if err := e.value(obj[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *cborEncoder) int(n int64) {
+	if n < 0 {
+		e.head(cborNegInt, uint64(-(n + 1)))
+		return
+	}
+	e.head(cborUint, uint64(n))
+}
+
+func (e *cborEncoder) bigInt(b *big.Int) {
+	if b.IsUint64() {
+		e.head(cborUint, b.Uint64())
+		return
+	}
+	major, tag := byte(cborUint), uint64(cborTagPosBignum)
+	n := b
+	if b.Sign() < 0 {
+		n = new(big.Int).Neg(b)
+		n.Sub(n, big.NewInt(1))
+		major, tag = cborNegInt, cborTagNegBignum
+	}
+	if n.IsUint64() {
+		e.head(major, n.Uint64())
+		return
+	}
+	e.head(cborTag, tag)
+	mag := n.Bytes()
+	e.head(cborBytes, uint64(len(mag)))
+	e.buf.Write(mag)
+}
+
+// float writes f in the shortest of the half, single and double precision
+// forms that holds it exactly.
+func (e *cborEncoder) float(f float64) {
+	var b [9]byte
+	if math.IsNaN(f) {
+		e.buf.Write([]byte{0xf9, 0x7e, 0x00})
+		return
+	}
+	if f32 := float32(f); float64(f32) == f {
+		if h, ok := float16Bits(f32); ok {
+			b[0] = 0xf9
+			binary.BigEndian.PutUint16(b[1:], h)
+			e.buf.Write(b[:3])
+			return
+		}
+		b[0] = 0xfa
+		binary.BigEndian.PutUint32(b[1:], math.Float32bits(f32))
+		e.buf.Write(b[:5])
+		return
+	}
+	b[0] = 0xfb
+	binary.BigEndian.PutUint64(b[1:], math.Float64bits(f))
+	e.buf.Write(b[:9])
+}
+
+// reducedNumber writes a number of the deterministic encoding: integral
+// values as integers, others as the shortest exact float.
+func (e *cborEncoder) reducedNumber(f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errors.New("easyjson: NaN and infinity are not valid JSON numbers")
+	}
+	if f != math.Trunc(f) {
+		e.float(f)
+		return nil
+	}
+	b, _ := big.NewFloat(f).Int(nil)
+	e.bigInt(b)
+	return nil
+}
+
+// float16Bits returns the IEEE 754 half precision bits of f if it can be
+// represented exactly.
+func float16Bits(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+	switch {
+	case exp == 0xff && mant == 0:
+		return sign | 0x7c00, true
+	case exp == 0 && mant == 0:
+		return sign, true
+	case exp == 0 || exp == 0xff:
+		return 0, false
+	}
+	e := exp - 127
+	switch {
+	case e >= -14 && e <= 15:
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(e+15)<<10 | uint16(mant>>13), true
+	case e >= -24 && e < -14:
+		// Subnormal: the value is m * 2^-24.
+		full := mant | 0x800000
+		shift := uint(-e - 1)
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	}
+	return 0, false
+}
+
+func float16ToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant != 0 {
+			return math.NaN()
+		}
+		f = math.Inf(1)
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
+
+// JSONFromCBOR decodes a single CBOR data item into a JSON value tree.
+// Byte strings become encoded strings (see AsBytes), bignums integers, the
+// undefined value null, and map keys that are not text strings are
+// stringified (42, true, null, the encoded bytes of a byte string key or the JSON text
+// of a composite key). Other tags are dropped, keeping their content.
+func JSONFromCBOR(b []byte, opts ...CBORDecodeOptions) (JSON, error) {
+	d := &cborDecoder{data: b}
+	if len(opts) > 0 {
+		d.opts = opts[0]
+	}
+	v, err := d.value()
+	if err != nil {
+		return NewJSONNull(), err
+	}
+	if d.pos < len(d.data) {
+		return NewJSONNull(), d.errorf("unexpected data after CBOR item")
+	}
+	return NewJSON(v), nil
+}
+
+type cborDecoder struct {
+	data  []byte
+	pos   int
+	opts  CBORDecodeOptions
+	depth int
+}
+
+func (d *cborDecoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("easyjson: invalid CBOR at offset %d: %s", d.pos, fmt.Sprintf(format, args...))
+}
+
+// head reads an initial byte and its argument. indefinite reports the
+// additional information 31.
+func (d *cborDecoder) head() (major byte, info byte, arg uint64, indefinite bool, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, false, d.errorf("unexpected end of input")
+	}
+	ib := d.data[d.pos]
+	major, info = ib>>5, ib&0x1f
+	d.pos++
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == 31:
+		return major, info, 0, true, nil
+	default:
+		d.pos--
+		return 0, 0, 0, false, d.errorf("reserved additional information %d", info)
+	}
+	if len(d.data)-d.pos < size {
+		return 0, 0, 0, false, d.errorf("unexpected end of input")
+	}
+	for _, c := range d.data[d.pos : d.pos+size] {
+		arg = arg<<8 | uint64(c)
+	}
+	d.pos += size
+	return major, info, arg, false, nil
+}
+
+func (d *cborDecoder) value() (interface{}, error) {
+	start := d.pos
+	major, info, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if indefinite && (major < cborBytes || major == cborTag) {
+		d.pos = start
+		return nil, d.errorf("invalid indefinite length for major type %d", major)
+	}
+	switch major {
+	case cborUint:
+		return parseNumber(json.Number(strconv.FormatUint(arg, 10)), d.opts.Numbers)
+	case cborNegInt:
+		n := new(big.Int).SetUint64(arg)
+		n.Neg(n).Sub(n, big.NewInt(1))
+		return parseNumber(json.Number(n.String()), d.opts.Numbers)
+	case cborBytes:
+		b, err := d.str(cborBytes, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		return encodeBytes(b, DefaultByteEncoding()), nil
+	case cborText:
+		b, err := d.str(cborText, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			d.pos = start
+			return nil, d.errorf("invalid UTF-8 in text string")
+		}
+		return string(b), nil
+	case cborArray:
+		return d.array(arg, indefinite)
+	case cborMap:
+		return d.object(arg, indefinite)
+	case cborTag:
+		return d.tagged(arg)
+	}
+
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23: // null, undefined
+		return nil, nil
+	case 25:
+		return floatForMode(float16ToFloat64(uint16(arg)), d.opts.Numbers), nil
+	case 26:
+		return floatForMode(float64(math.Float32frombits(uint32(arg))), d.opts.Numbers), nil
+	case 27:
+		return floatForMode(math.Float64frombits(arg), d.opts.Numbers), nil
+	case 31:
+		d.pos = start
+		return nil, d.errorf("unexpected break")
+	}
+	d.pos = start
+	return nil, d.errorf("unsupported simple value %d", arg)
+}
+
+// str reads the content of a byte or text string.
+func (d *cborDecoder) str(major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, d.errorf("string length %d exceeds input", n)
+		}
+		b := d.data[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		return b, nil
+	}
+	var out []byte
+	for {
+		start := d.pos
+		m, info, arg, ind, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m == cborSimple && info == 31 {
+			return out, nil
+		}
+		if m != major || ind {
+			d.pos = start
+			return nil, d.errorf("invalid chunk in indefinite-length string")
+		}
+		chunk, err := d.str(major, arg, false)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+	}
+}
+
+func (d *cborDecoder) enter() error {
+	d.depth++
+	if d.depth > defaultMaxDepth {
+		return d.errorf("nesting depth exceeds %d", defaultMaxDepth)
+	}
+	return nil
+}
+
+// atBreak consumes the break stop code ending an indefinite-length item if
+// it comes next.
+func (d *cborDecoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == 0xff {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *cborDecoder) array(n uint64, indefinite bool) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	if !indefinite && n > uint64(len(d.data)-d.pos) {
+		return nil, d.errorf("array length %d exceeds input", n)
+	}
+	arr := make([]interface{}, 0, n)
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite && d.atBreak() {
+			break
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func (d *cborDecoder) object(n uint64, indefinite bool) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	if !indefinite && n > uint64(len(d.data)-d.pos)/2 {
+		return nil, d.errorf("map length %d exceeds input", n)
+	}
+	var obj interface{} = make(map[string]interface{}, n)
+	if d.opts.PreserveKeyOrder {
+		obj = NewOrderedObject()
+	}
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite && d.atBreak() {
+			break
+		}
+		start := d.pos
+		kv, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		key, err := jvKeyString(kv)
+		if err != nil {
+			d.pos = start
+			return nil, d.errorf("unsupported map key: %v", err)
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		jvObjectSet(obj, key, v)
+	}
+	return obj, nil
+}
+
+func (d *cborDecoder) tagged(tag uint64) (interface{}, error) {
+	if tag != cborTagPosBignum && tag != cborTagNegBignum {
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer func() { d.depth-- }()
+		return d.value()
+	}
+	start := d.pos
+	major, _, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != cborBytes {
+		d.pos = start
+		return nil, d.errorf("bignum content is not a byte string")
+	}
+	mag, err := d.str(cborBytes, arg, indefinite)
+	if err != nil {
+		return nil, err
+	}
+	n := new(big.Int).SetBytes(mag)
+	if tag == cborTagNegBignum {
+		n.Neg(n).Sub(n, big.NewInt(1))
+	}
+	return parseNumber(json.Number(n.String()), d.opts.Numbers)
+}
@@ -0,0 +1,164 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestToCBOR_RFC8949Examples(t *testing.T) {
+	big64, _ := new(big.Int).SetString("18446744073709551616", 10)
+	nbig64, _ := new(big.Int).SetString("-18446744073709551617", 10)
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{0, "00"}, {23, "17"}, {24, "1818"}, {1000, "1903e8"}, {uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{big64, "c249010000000000000000"}, {nbig64, "c349010000000000000000"},
+		{-1, "20"}, {int64(-1000), "3903e7"},
+		{0.0, "f90000"}, {math.Copysign(0, -1), "f98000"}, {1.0, "f93c00"}, {1.1, "fb3ff199999999999a"},
+		{1.5, "f93e00"}, {65504.0, "f97bff"}, {100000.0, "fa47c35000"}, {5.960464477539063e-8, "f90001"},
+		{-4.0, "f9c400"}, {math.Inf(1), "f97c00"}, {math.NaN(), "f97e00"},
+		{false, "f4"}, {true, "f5"}, {nil, "f6"},
+		{"", "60"}, {"ü", "62c3bc"},
+		{[]interface{}{1, []interface{}{2, 3}}, "8201820203"},
+		{map[string]interface{}{"a": 1, "b": []interface{}{2, 3}}, "a26161016162820203"},
+	}
+	for _, c := range cases {
+		got, err := JSON{Value: c.v}.ToCBOR()
+		if err != nil {
+			t.Errorf("%v: %v", c.v, err)
+			continue
+		}
+		if hex.EncodeToString(got) != c.want {
+			t.Errorf("%v: got %x, want %s", c.v, got, c.want)
+		}
+	}
+}
+
+func TestJSONFromCBOR(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"a26161016162820203", `{"a":1,"b":[2,3]}`},
+		{"9f018202039f0405ffff", `[1,[2,3],[4,5]]`},
+		{"bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`},
+		{"7f657374726561646d696e67ff", `"streaming"`},
+		{"5f42010243030405ff", `"0102030405"`},
+		{"a3f66161016162f56163", `{"1":"b","null":"a","true":"c"}`},
+		{"a1420102f4", `{"0102":false}`},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{"f7", `null`},
+		{"f97bff", `65504`},
+		{"3bffffffffffffffff", `-18446744073709552000`},
+	}
+	for _, c := range cases {
+		in, _ := hex.DecodeString(c.in)
+		j, err := JSONFromCBOR(in)
+		if err != nil {
+			t.Errorf("%s: %v", c.in, err)
+			continue
+		}
+		if got := j.ToString(); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.in, got, c.want)
+		}
+	}
+
+	in, _ := hex.DecodeString("c349010000000000000000")
+	j, err := JSONFromCBOR(in, CBORDecodeOptions{Numbers: NumberTyped})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := j.AsBigInt(); !ok || n.String() != "-18446744073709551617" {
+		t.Fatalf("negative bignum: %v", j.Value)
+	}
+
+	in, _ = hex.DecodeString("a2617a01616102")
+	j, err = JSONFromCBOR(in, CBORDecodeOptions{PreserveKeyOrder: true})
+	if err != nil || j.ToString() != `{"z":1,"a":2}` {
+		t.Fatalf("key order: %s %v", j.ToString(), err)
+	}
+
+	for _, bad := range []string{"", "18", "62c3", "1c", "ff", "a1", "9f01", "0102", "62ff00", "f820", "5f6161ff", "5b000000ffffffffff"} {
+		in, _ := hex.DecodeString(bad)
+		if _, err := JSONFromCBOR(in); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestJSONFromCBOR_MisplacedBreak(t *testing.T) {
+	for _, c := range []struct {
+		name, in string
+	}{
+		{"definite array in indefinite array", "9f81ff"},
+		{"definite array between elements", "9f0181ff02ff"},
+		{"definite map value", "bf6161a16162ffff"},
+		{"definite map key", "9fa1ffff"},
+		{"tag content", "9fc0ffff"},
+		{"top level", "ff"},
+	} {
+		in, _ := hex.DecodeString(c.in)
+		_, err := JSONFromCBOR(in)
+		if err == nil || !strings.Contains(err.Error(), "unexpected break") {
+			t.Errorf("%s (%s): expected an unexpected break error, got %v", c.name, c.in, err)
+		}
+	}
+}
+
+func TestCBOR_RoundTripAndBytes(t *testing.T) {
+	j, _ := JSONFromString(`{"name":"svc","ports":[80,443],"ratio":0.25,"nested":{"ok":true,"none":null},"big":1e300}`)
+	b, err := j.ToCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := JSONFromCBOR(b)
+	if err != nil || !back.Equals(j) {
+		t.Fatalf("round trip: %v %v", back.ToString(), err)
+	}
+
+	data := NewJSONBytes([]byte{0xde, 0xad, 0xbe, 0xef})
+	plain, _ := data.ToCBOR()
+	asBytes, _ := data.ToCBOR(CBOREncodeOptions{StringsAsBytes: true})
+	if hex.EncodeToString(plain) != "686465616462656566" || hex.EncodeToString(asBytes) != "44deadbeef" {
+		t.Fatalf("hex strings: %x %x", plain, asBytes)
+	}
+	raw, _ := JSON{Value: []byte{0xde, 0xad, 0xbe, 0xef}}.ToCBOR()
+	if !bytes.Equal(raw, asBytes) {
+		t.Fatalf("[]byte values must be byte strings: %x", raw)
+	}
+	decoded, _ := JSONFromCBOR(asBytes)
+	if raw, ok := decoded.AsBytes(); !ok || !bytes.Equal(raw, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Fatalf("byte strings must decode to NewJSONBytes hex: %v", decoded.Value)
+	}
+}
+
+func TestToCBOR_Deterministic(t *testing.T) {
+	a := NewJSONOrderedObject()
+	a.SetByPath("bb", NewJSON(1.0))
+	a.SetByPath("a", NewJSON(int64(-3)))
+	a.SetByPath("c", NewJSON([]interface{}{0.5, uint8(7)}))
+	b, _ := JSONFromString(`{"c":[0.5,7],"a":-3,"bb":1}`)
+
+	da, err := a.ToCBOR(CBOREncodeOptions{Deterministic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := b.ToCBOR(CBOREncodeOptions{Deterministic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(da, db) {
+		t.Fatalf("deterministic encodings differ: %x %x", da, db)
+	}
+	if want := "a3616122616382f938000762626201"; hex.EncodeToString(da) != want {
+		t.Fatalf("got %x, want %s", da, want)
+	}
+	if _, err := NewJSON(math.Inf(1)).ToCBOR(CBOREncodeOptions{Deterministic: true}); err == nil {
+		t.Fatal("expected infinity to be rejected")
+	}
+}
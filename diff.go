@@ -0,0 +1,242 @@
+package easyjson
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeType classifies a single difference reported by Diff.
+type ChangeType int
+
+const (
+	// ChangeAdded means the value exists only in the second document.
+	ChangeAdded ChangeType = iota
+	// ChangeRemoved means the value exists only in the first document.
+	ChangeRemoved
+	// ChangeModified means the value exists in both documents but differs.
+	ChangeModified
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// Change describes one difference between two JSON documents.
+// Path uses the dot path syntax of GetByPath; Pointer is the same location
+// as an RFC 6901 JSON Pointer, which stays unambiguous for any key.
+// OldValue is null for added values and NewValue is null for removed ones.
+type Change struct {
+	Type     ChangeType
+	Path     string
+	Pointer  string
+	OldValue JSON
+	NewValue JSON
+}
+
+// DiffOptions configures Diff.
+type DiffOptions struct {
+	// UnorderedArrays compares arrays as multisets using the canonical form
+	// of Normalize, so element order is ignored. Added and removed elements
+	// are reported at their index in the respective document.
+	UnorderedArrays bool
+	// IgnoreNumberTypes treats numbers of different Go types (int, float64,
+	// json.Number, ...) as equal when their values are equal.
+	IgnoreNumberTypes bool
+	// Delimiter is used to build Change.Path; defaults to ".".
+	Delimiter string
+}
+
+// Diff reports the structural differences between a and b.
+// Object members are visited in sorted key order. An undefined document
+// counts as missing, so comparing it with any defined one, null included,
+// reports an added or removed root.
+func Diff(a, b JSON, opts ...DiffOptions) []Change {
+	d := differ{}
+	if len(opts) > 0 {
+		d.opts = opts[0]
+	}
+	if d.opts.Delimiter == "" {
+		d.opts.Delimiter = "."
+	}
+	switch au, bu := a.IsUndefined(), b.IsUndefined(); {
+	case au && bu:
+	case au:
+		d.add(ChangeAdded, nil, nil, b.Value)
+	case bu:
+		d.add(ChangeRemoved, nil, a.Value, nil)
+	default:
+		d.diff(nil, a.Value, b.Value)
+	}
+	return d.changes
+}
+
+type differ struct {
+	opts    DiffOptions
+	changes []Change
+}
+
+func (d *differ) add(t ChangeType, path []string, oldV, newV interface{}) {
+	d.changes = append(d.changes, Change{
+		Type:     t,
+		Path:     strings.Join(path, d.opts.Delimiter),
+		Pointer:  BuildPointer(path...),
+		OldValue: NewJSON(oldV),
+		NewValue: NewJSON(newV),
+	})
+}
+
+func (d *differ) scalarEqual(a, b interface{}) bool {
+	if d.opts.IgnoreNumberTypes {
+		return jvEqual(a, b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// elementKey returns the key under which diffMultisets matches array
+// elements. Nested arrays are unordered as well. Unless IgnoreNumberTypes is
+// set, numbers carry their Go type, as in scalarEqual.
+func (d *differ) elementKey(v interface{}) string {
+	if d.opts.IgnoreNumberTypes {
+		return canonicalString(normalizeValue(v))
+	}
+	var b strings.Builder
+	typedCanonical(&b, v)
+	return b.String()
+}
+
+func typedCanonical(b *strings.Builder, v interface{}) {
+	if obj, ok := jvObject(v); ok {
+		b.WriteByte('{')
+		for i, k := range sortedKeys(obj) {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Quote(k))
+			b.WriteByte(':')
+			typedCanonical(b, obj[k])
+		}
+		b.WriteByte('}')
+		return
+	}
+	if a, ok := v.([]interface{}); ok {
+		keys := make([]string, len(a))
+		for i, e := range a {
+			var eb strings.Builder
+			typedCanonical(&eb, e)
+			keys[i] = eb.String()
+		}
+		sort.Strings(keys)
+		b.WriteString("[" + strings.Join(keys, ",") + "]")
+		return
+	}
+	if _, ok := numberToFloat64(v); ok {
+		fmt.Fprintf(b, "%T(%v)", v, v)
+		return
+	}
+	b.WriteString(canonicalString(v))
+}
+
+func (d *differ) diff(path []string, a, b interface{}) {
+	if av, ok := jvObject(a); ok {
+		if bv, ok := jvObject(b); ok {
+			d.diffObjects(path, av, bv)
+			return
+		}
+	} else if av, ok := a.([]interface{}); ok {
+		if bv, ok := b.([]interface{}); ok {
+			if d.opts.UnorderedArrays {
+				d.diffMultisets(path, av, bv)
+			} else {
+				d.diffArrays(path, av, bv)
+			}
+			return
+		}
+	} else if !jvIsContainer(b) && d.scalarEqual(a, b) {
+		return
+	}
+	d.add(ChangeModified, path, a, b)
+}
+
+func (d *differ) diffObjects(path []string, a, b map[string]interface{}) {
+	child := func(k string) []string {
+		return append(path[:len(path):len(path)], k)
+	}
+	keys := make(map[string]interface{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = nil
+	}
+	for k := range b {
+		keys[k] = nil
+	}
+	for _, k := range sortedKeys(keys) {
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inB:
+			d.add(ChangeRemoved, child(k), av, nil)
+		case !inA:
+			d.add(ChangeAdded, child(k), nil, bv)
+		default:
+			d.diff(child(k), av, bv)
+		}
+	}
+}
+
+func (d *differ) diffArrays(path []string, a, b []interface{}) {
+	child := func(i int) []string {
+		return append(path[:len(path):len(path)], strconv.Itoa(i))
+	}
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(b):
+			d.add(ChangeRemoved, child(i), a[i], nil)
+		case i >= len(a):
+			d.add(ChangeAdded, child(i), nil, b[i])
+		default:
+			d.diff(child(i), a[i], b[i])
+		}
+	}
+}
+
+func (d *differ) diffMultisets(path []string, a, b []interface{}) {
+	child := func(i int) []string {
+		return append(path[:len(path):len(path)], strconv.Itoa(i))
+	}
+	canon := d.elementKey
+
+	pending := map[string][]int{}
+	for i, v := range a {
+		c := canon(v)
+		pending[c] = append(pending[c], i)
+	}
+	matched := make([]bool, len(a))
+	var added []int
+	for i, v := range b {
+		c := canon(v)
+		if idxs := pending[c]; len(idxs) > 0 {
+			matched[idxs[0]] = true
+			pending[c] = idxs[1:]
+			continue
+		}
+		added = append(added, i)
+	}
+	for i, m := range matched {
+		if !m {
+			d.add(ChangeRemoved, child(i), a[i], nil)
+		}
+	}
+	for _, i := range added {
+		d.add(ChangeAdded, child(i), nil, b[i])
+	}
+}
@@ -0,0 +1,106 @@
+package easyjson
+
+import (
+	"testing"
+)
+
+func changeSummary(changes []Change) []string {
+	out := make([]string, len(changes))
+	for i, c := range changes {
+		out[i] = c.Type.String() + " " + c.Path + " " + c.OldValue.ToString() + " -> " + c.NewValue.ToString()
+	}
+	return out
+}
+
+func assertChanges(t *testing.T, got []Change, want ...string) {
+	t.Helper()
+	sum := changeSummary(got)
+	if len(sum) != len(want) {
+		t.Fatalf("change count mismatch\nwant: %q\ngot : %q", want, sum)
+	}
+	for i := range want {
+		if sum[i] != want[i] {
+			t.Fatalf("change %d mismatch\nwant: %q\ngot : %q", i, want, sum)
+		}
+	}
+}
+
+func TestDiff_Objects(t *testing.T) {
+	a := mustJSONFromString(t, `{"a":1,"b":{"c":"x","d":true},"gone":null}`)
+	b := mustJSONFromString(t, `{"a":1,"b":{"c":"y","e":[1]},"new":2}`)
+	assertChanges(t, Diff(a, b),
+		"changed b.c \"x\" -> \"y\"",
+		"removed b.d true -> null",
+		"added b.e null -> [1]",
+		"removed gone null -> null",
+		"added new null -> 2",
+	)
+	if len(Diff(a, a.Clone())) != 0 {
+		t.Fatalf("equal documents must have no changes")
+	}
+}
+
+func TestDiff_OrderedArrays(t *testing.T) {
+	a := mustJSONFromString(t, `{"arr":[1,2,3]}`)
+	b := mustJSONFromString(t, `{"arr":[1,5]}`)
+	assertChanges(t, Diff(a, b),
+		"changed arr.1 2 -> 5",
+		"removed arr.2 3 -> null",
+	)
+}
+
+func TestDiff_UnorderedArrays(t *testing.T) {
+	a := mustJSONFromString(t, `{"arr":[1,{"k":[2,1]},3,3]}`)
+	b := mustJSONFromString(t, `{"arr":[3,{"k":[1,2]},1,4]}`)
+	assertChanges(t, Diff(a, b, DiffOptions{UnorderedArrays: true}),
+		"removed arr.3 3 -> null",
+		"added arr.3 null -> 4",
+	)
+}
+
+func TestDiff_NumberTypes(t *testing.T) {
+	a := NewJSON(map[string]interface{}{"n": 1, "m": int64(2)})
+	b := mustJSONFromString(t, `{"n":1,"m":2.5}`)
+
+	if got := Diff(a, b); len(got) != 2 {
+		t.Fatalf("strict comparison expected 2 changes, got %q", changeSummary(got))
+	}
+	assertChanges(t, Diff(a, b, DiffOptions{IgnoreNumberTypes: true}),
+		"changed m 2 -> 2.5",
+	)
+}
+
+func TestDiff_UnorderedArraysNumberTypes(t *testing.T) {
+	a := NewJSON([]interface{}{1, 2.0, []interface{}{int64(3), "x"}})
+	b := mustJSONFromString(t, `[2,["x",3],1]`)
+
+	assertChanges(t, Diff(a, b, DiffOptions{UnorderedArrays: true}),
+		"removed 0 1 -> null",
+		"removed 2 [3,\"x\"] -> null",
+		"added 1 null -> [\"x\",3]",
+		"added 2 null -> 1",
+	)
+	if got := Diff(a, b, DiffOptions{UnorderedArrays: true, IgnoreNumberTypes: true}); len(got) != 0 {
+		t.Fatalf("expected no changes, got %q", changeSummary(got))
+	}
+}
+
+func TestDiff_PointerAndDelimiter(t *testing.T) {
+	a := mustJSONFromString(t, `{"a.b":{"c":1}}`)
+	b := mustJSONFromString(t, `{"a.b":{"c":2}}`)
+	changes := Diff(a, b, DiffOptions{Delimiter: "/"})
+	if len(changes) != 1 {
+		t.Fatalf("expected one change, got %d", len(changes))
+	}
+	if changes[0].Path != "a.b/c" || changes[0].Pointer != "/a.b/c" {
+		t.Fatalf("unexpected path %q / pointer %q", changes[0].Path, changes[0].Pointer)
+	}
+	if changes[0].Type != ChangeModified {
+		t.Fatalf("expected modified change, got %s", changes[0].Type)
+	}
+
+	root := Diff(NewJSON("x"), NewJSONArray())
+	if len(root) != 1 || root[0].Path != "" || root[0].Pointer != "" {
+		t.Fatalf("expected a single root change, got %q", changeSummary(root))
+	}
+}
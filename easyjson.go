@@ -3,8 +3,10 @@
 package easyjson
 
 import (
-	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
 	"reflect"
 	"sort"
 	"strconv"
@@ -12,10 +14,20 @@ import (
 )
 
 // JSON represents a JSON value that can be manipulated using path-based operations.
+// The zero value is JSON null; NewJSONUndefined creates a value that stands
+// for a missing one and is returned by lookups such as At.
 type JSON struct {
 	Value interface{}
 }
 
+// undefinedValue is the Value of an undefined JSON. It encodes as null and is
+// never stored inside a document: setting an undefined value removes it.
+type undefinedValue struct{}
+
+func (undefinedValue) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
 // NewJSON creates a new JSON instance from any Go value.
 func NewJSON(value interface{}) JSON {
 	if value == nil {
@@ -36,9 +48,11 @@ func NewJSON(value interface{}) JSON {
 	return JSON{Value: value}
 }
 
-// NewJSONBytes creates a new JSON instance from byte slice, encoding it as hex string.
+// NewJSONBytes creates a new JSON instance from byte slice, encoding it as a
+// string in the default byte encoding (hex unless changed with
+// SetDefaultByteEncoding).
 func NewJSONBytes(value []byte) JSON {
-	codedBytes := hex.EncodeToString(value)
+	codedBytes := encodeBytes(value, DefaultByteEncoding())
 	var j JSON
 	j.Value = codedBytes
 	return j
@@ -49,15 +63,24 @@ func NewJSONNull() JSON {
 	return NewJSON(nil)
 }
 
+// NewJSONUndefined creates a JSON instance representing a missing value.
+// It serializes like null but is reported by IsUndefined rather than IsNull.
+func NewJSONUndefined() JSON {
+	return JSON{Value: undefinedValue{}}
+}
+
 // NewJSONObject creates a new empty JSON object.
 func NewJSONObject() JSON {
 	return NewJSON(make(map[string]interface{}))
 }
 
 // NewJSONObjectWithKeyValue creates a new JSON object with a single key-value pair.
+// An undefined value leaves the object empty.
 func NewJSONObjectWithKeyValue(key string, value JSON) JSON {
 	m := make(map[string]interface{})
-	m[key] = value.Value
+	if !value.IsUndefined() {
+		m[key] = value.Value
+	}
 	return NewJSON(m)
 }
 
@@ -105,8 +128,10 @@ func NewJSONArray() JSON {
 }*/
 
 // Equals compares two JSON values for deep equality.
+// Use Diff to find out where two values differ.
+// Key order does not matter, so ordered and plain objects can be equal.
 func (j1 JSON) Equals(j2 JSON) bool {
-	return reflect.DeepEqual(j1.Value, j2.Value)
+	return jvDeepEqual(j1.Value, j2.Value)
 }
 
 type pathIter struct {
@@ -131,76 +156,94 @@ func (it *pathIter) next() (tok string, ok bool) {
 	return tok, true
 }
 
-func (j JSON) PathExists(p string, delimiter ...string) bool {
-	delim := byte('.')
+// pathDelimiter returns the first byte of the optional delimiter argument, defaulting to '.'.
+func pathDelimiter(delimiter ...string) byte {
 	if len(delimiter) > 0 && len(delimiter[0]) > 0 {
-		delim = delimiter[0][0]
-	}
-	if p == "" {
-		return true
+		return delimiter[0][0]
 	}
+	return '.'
+}
 
-	cur := j.Value
+// jvGetValueByPath walks the path and returns the value found, or a *PathError
+// describing the segment at which the walk failed.
+func jvGetValueByPath(jv interface{}, p string, delim byte) (interface{}, error) {
+	cur := jv
 	it := pathIter{s: p, i: 0, delim: delim}
-	for {
+	for n := 0; ; n++ {
+		pos := it.i
 		tok, ok := it.next()
 		if !ok || tok == "" {
 			break
 		}
-		switch v := cur.(type) {
-		case map[string]interface{}:
-			nv, ok := v[tok]
+		if obj, ok := jvObject(cur); ok {
+			nv, ok := obj[tok]
 			if !ok {
-				return false
+				return nil, newPathError(p, tok, n, pos, ErrPathNotFound)
 			}
 			cur = nv
+			continue
+		}
+		switch v := cur.(type) {
 		case []interface{}:
 			idx, err := strconv.Atoi(tok)
-			if err != nil || idx < 0 || idx >= len(v) {
-				return false
+			if err != nil {
+				return nil, newPathError(p, tok, n, pos, ErrInvalidIndex)
+			}
+			if idx < 0 || idx >= len(v) {
+				return nil, newPathError(p, tok, n, pos, ErrIndexOutOfRange)
 			}
 			cur = v[idx]
 		default:
-			return false
+			return nil, newPathError(p, tok, n, pos, ErrNotContainer)
 		}
 	}
-	return true
+	return cur, nil
 }
 
+// PathExists checks whether a value exists at the specified path.
+func (j JSON) PathExists(p string, delimiter ...string) bool {
+	_, err := jvGetValueByPath(j.Value, p, pathDelimiter(delimiter...))
+	return err == nil
+}
+
+// GetByPath returns the value at the specified path, or null if the path does not exist.
 func (j JSON) GetByPath(p string, delimiter ...string) JSON {
-	delim := byte('.')
-	if len(delimiter) > 0 && len(delimiter[0]) > 0 {
-		delim = delimiter[0][0]
+	v, err := jvGetValueByPath(j.Value, p, pathDelimiter(delimiter...))
+	if err != nil {
+		return NewJSONNull()
 	}
-	if p == "" {
-		return j
+	return NewJSON(v)
+}
+
+// Lookup returns the value at the specified path and whether it exists,
+// in a single walk. Unlike GetByPath it tells an explicit null apart from a
+// missing value, which is returned as undefined.
+func (j JSON) Lookup(p string, delimiter ...string) (JSON, bool) {
+	if j.IsUndefined() {
+		return j, false
+	}
+	v, err := jvGetValueByPath(j.Value, p, pathDelimiter(delimiter...))
+	if err != nil {
+		return NewJSONUndefined(), false
 	}
+	return NewJSON(v), true
+}
 
-	cur := j.Value
-	it := pathIter{s: p, i: 0, delim: delim}
-	for {
-		tok, ok := it.next()
-		if !ok || tok == "" {
-			break
-		}
-		switch v := cur.(type) {
-		case map[string]interface{}:
-			nv, ok := v[tok]
-			if !ok {
-				return NewJSONNull()
-			}
-			cur = nv
-		case []interface{}:
-			idx, err := strconv.Atoi(tok)
-			if err != nil || idx < 0 || idx >= len(v) {
-				return NewJSONNull()
-			}
-			cur = v[idx]
-		default:
-			return NewJSONNull()
-		}
+// At is like Lookup but only returns the value, so lookups can be chained:
+// once a path is missing, every following At returns undefined as well.
+func (j JSON) At(p string, delimiter ...string) JSON {
+	v, _ := j.Lookup(p, delimiter...)
+	return v
+}
+
+// TryGetByPath is like GetByPath but returns a *PathError explaining why the
+// path could not be resolved.
+func (j JSON) TryGetByPath(p string, delimiter ...string) (JSON, error) {
+	v, err := jvGetValueByPath(j.Value, p, pathDelimiter(delimiter...))
+	if err != nil {
+		return NewJSONNull(), err
 	}
-	return NewJSON(cur)
+	return NewJSON(v), nil
 }
 
 // GetByPathPtr returns a pointer to the JSON value at the specified path.
@@ -229,47 +272,47 @@ func nextPathToken(p string, i int, delim byte) (tok string, next int, ok bool)
 	return tok, j, true
 }
 
-func jvSetValueByPath(parent *interface{}, parentKeyOrIdForThisValue string, jv *interface{}, p string, v *interface{}, delimiter string) bool {
+func jvSetValueByPath(parent *interface{}, parentKeyOrIdForThisValue string, jv *interface{}, p string, v *interface{}, delimiter string) error {
 	delim := byte('.')
 	if delimiter != "" {
 		delim = delimiter[0]
 	}
 
 	// Recursive setter: walks the path and sets value, creating intermediate nodes.
-	var set func(cur interface{}, pos int) (interface{}, bool)
-	set = func(cur interface{}, pos int) (interface{}, bool) {
+	var set func(cur interface{}, pos int, n int) (interface{}, error)
+	set = func(cur interface{}, pos int, n int) (interface{}, error) {
 		tok, next, ok := nextPathToken(p, pos, delim)
 		if !ok || tok == "" {
-			return nil, false
+			return nil, newPathError(p, tok, n, pos, ErrInvalidPath)
 		}
 		last := next >= len(p)
 
-		switch cv := cur.(type) {
-
-		case map[string]interface{}:
+		if obj, ok := jvObject(cur); ok {
 			// Object case: always create missing children as objects (STRICT MODE).
 			if last {
-				cv[tok] = *v
-				return cv, true
+				jvObjectSet(cur, tok, *v)
+				return cur, nil
 			}
-			child, exists := cv[tok]
+			child, exists := obj[tok]
 			if !exists || child == nil {
 				// <<< FIX: no look-ahead to decide []interface{} by numeric token >>>
-				cv[tok] = map[string]interface{}{}
-				child = cv[tok]
+				child = jvNewObjectLike(cur)
+				jvObjectSet(cur, tok, child)
 			}
-			newChild, ok := set(child, next)
-			if !ok {
-				return cur, false
+			newChild, err := set(child, next, n+1)
+			if err != nil {
+				return cur, err
 			}
-			cv[tok] = newChild
-			return cv, true
+			jvObjectSet(cur, tok, newChild)
+			return cur, nil
+		}
 
+		switch cv := cur.(type) {
 		case []interface{}:
 			// Array case: indices must be numeric; negative index means push (last token only).
 			id, err := strconv.Atoi(tok)
 			if err != nil {
-				return cur, false
+				return cur, newPathError(p, tok, n, pos, ErrInvalidIndex)
 			}
 			if last {
 				if id < 0 {
@@ -277,86 +320,93 @@ func jvSetValueByPath(parent *interface{}, parentKeyOrIdForThisValue string, jv
 				} else {
 					jvSetArrayValue(&cv, id, *v)
 				}
-				return cv, true
+				return cv, nil
 			}
 			if id < 0 || id >= len(cv) {
-				return cur, false
+				return cur, newPathError(p, tok, n, pos, ErrIndexOutOfRange)
 			}
-			newChild, ok := set(cv[id], next)
-			if !ok {
-				return cur, false
+			newChild, err := set(cv[id], next, n+1)
+			if err != nil {
+				return cur, err
 			}
 			cv[id] = newChild
-			return cv, true
+			return cv, nil
 
 		default:
 			// Neither object nor array — cannot traverse further.
-			return cur, false
+			return cur, newPathError(p, tok, n, pos, ErrNotContainer)
 		}
 	}
 
-	newRoot, ok := set(*jv, 0)
-	if !ok {
-		return false
+	newRoot, err := set(*jv, 0, 0)
+	if err != nil {
+		return err
 	}
 	*jv = newRoot
-	return true
+	return nil
 }
 
-func jvRemoveValueByPath(jv *interface{}, p string, delimiter string) bool {
+func jvRemoveValueByPath(jv *interface{}, p string, delimiter string) error {
 	delim := byte('.')
 	if delimiter != "" {
 		delim = delimiter[0]
 	}
-	var rm func(cur *interface{}, idx int) bool
-	rm = func(cur *interface{}, idx int) bool {
+	var rm func(cur *interface{}, idx int, n int) error
+	rm = func(cur *interface{}, idx int, n int) error {
 		tok, next, ok := nextPathToken(p, idx, delim)
 		if !ok || tok == "" {
 			*cur = nil
-			return true
+			return nil
 		}
 		last := next >= len(p)
-		switch cv := (*cur).(type) {
-		case map[string]interface{}:
-			if last {
-				delete(cv, tok)
-				return true
-			}
-			nxt, ok := cv[tok]
+		if obj, ok := jvObject(*cur); ok {
+			nxt, ok := obj[tok]
 			if !ok {
-				return true
+				return newPathError(p, tok, n, idx, ErrPathNotFound)
+			}
+			if last {
+				jvObjectDelete(*cur, tok)
+				return nil
 			}
-			return rm(&nxt, next)
+			return rm(&nxt, next, n+1)
+		}
+		switch cv := (*cur).(type) {
 		case []interface{}:
 			id, err := strconv.Atoi(tok)
-			if err != nil || id < 0 || id >= len(cv) {
-				return false
+			if err != nil {
+				return newPathError(p, tok, n, idx, ErrInvalidIndex)
+			}
+			if id < 0 || id >= len(cv) {
+				return newPathError(p, tok, n, idx, ErrIndexOutOfRange)
 			}
 			if last {
 				cv[id] = nil
-				return true
+				return nil
 			}
-			return rm(&cv[id], next)
+			return rm(&cv[id], next, n+1)
 		default:
-			return false
+			return newPathError(p, tok, n, idx, ErrNotContainer)
 		}
 	}
-	return rm(jv, 0)
+	return rm(jv, 0, 0)
 }
 
 func jvDeepMerge(jv1 *interface{}, jv2 *interface{}) {
-	switch x1 := (*jv1).(type) {
-	case map[string]interface{}:
-		if x2, ok := (*jv2).(map[string]interface{}); ok {
-			for k2, v2 := range x2 {
+	if x1, ok := jvObject(*jv1); ok {
+		if x2, ok := jvObject(*jv2); ok {
+			for _, k2 := range jvObjectKeys(*jv2) {
+				v2 := x2[k2]
 				if v1, exists := x1[k2]; exists {
 					jvDeepMerge(&v1, &v2)
-					x1[k2] = v1
+					jvObjectSet(*jv1, k2, v1)
 				} else {
-					x1[k2] = v2
+					jvObjectSet(*jv1, k2, v2)
 				}
 			}
 		}
+		return
+	}
+	switch x1 := (*jv1).(type) {
 	case []interface{}:
 		if x2, ok := (*jv2).([]interface{}); ok {
 			a1 := x1
@@ -404,22 +454,40 @@ func jvSetArrayValue(jArray *[]interface{}, id int, v interface{}) bool {
 }
 
 // SetByPath sets a value at the specified path in the JSON structure.
-// Creates intermediate objects/arrays as needed.
+// Creates intermediate objects/arrays as needed. Setting an undefined value
+// removes the path like RemoveByPath instead.
 func (j *JSON) SetByPath(p string, v JSON, delimiter ...string) bool {
+	return j.TrySetByPath(p, v, delimiter...) == nil
+}
+
+// TrySetByPath is like SetByPath but returns a *PathError explaining why the
+// value could not be set.
+func (j *JSON) TrySetByPath(p string, v JSON, delimiter ...string) error {
 	delim := "."
 	if len(delimiter) > 0 {
 		delim = delimiter[0]
 	}
+	if v.IsUndefined() && p != "" {
+		if err := j.TryRemoveByPath(p, delim); err != nil && !errors.Is(err, ErrPathNotFound) {
+			return err
+		}
+		return nil
+	}
 	return jvSetValueByPath(nil, "", &j.Value, p, &v.Value, delim)
 }
 
 // SetByPathCustomDelimiter sets a value using a custom path delimiter.
 func (j *JSON) SetByPathCustomDelimiter(p string, v JSON, delimiter string) bool {
-	return jvSetValueByPath(nil, "", &j.Value, p, &v.Value, delimiter)
+	return j.TrySetByPath(p, v, delimiter) == nil
 }
 
 // DeepMerge merges another JSON value into this one recursively.
+// Use DeepMergeWith to choose different array, scalar and type-mismatch policies.
+// Merging an undefined value changes nothing.
 func (j *JSON) DeepMerge(v JSON) {
+	if v.IsUndefined() {
+		return
+	}
 	if j == nil {
 		j = &v
 	} else {
@@ -428,7 +496,16 @@ func (j *JSON) DeepMerge(v JSON) {
 }
 
 // RemoveByPath removes a value at the specified path.
+// A missing object member counts as already removed.
 func (j *JSON) RemoveByPath(p string, delimiter ...string) bool {
+	err := j.TryRemoveByPath(p, delimiter...)
+	return err == nil || errors.Is(err, ErrPathNotFound)
+}
+
+// TryRemoveByPath is like RemoveByPath but returns a *PathError explaining
+// why the value could not be removed, including ErrPathNotFound for a
+// missing object member.
+func (j *JSON) TryRemoveByPath(p string, delimiter ...string) error {
 	delim := "."
 	if len(delimiter) > 0 {
 		delim = delimiter[0]
@@ -453,6 +530,12 @@ func deepCopy(v interface{}) interface{} {
 			m[k] = deepCopy(vv)
 		}
 		return m
+	case *OrderedObject:
+		o := &OrderedObject{keys: x.Keys(), values: make(map[string]interface{}, x.Len())}
+		for k, vv := range x.values {
+			o.values[k] = deepCopy(vv)
+		}
+		return o
 	case []interface{}:
 		s := make([]interface{}, len(x))
 		for i, vv := range x {
@@ -488,6 +571,8 @@ func normalizeValue(v interface{}) interface{} {
 			m[k] = normalizeValue(vv)
 		}
 		return m
+	case *OrderedObject:
+		return normalizeValue(x.Map())
 
 	case []interface{}:
 		arr := make([]interface{}, 0, len(x))
@@ -530,6 +615,11 @@ func normalizeValue(v interface{}) interface{} {
 		}
 		// if parsing fails, keep it as a string
 		return x.String()
+	case *big.Int:
+		if f, ok := numberToFloat64(x); ok {
+			return f
+		}
+		return x
 	default:
 		return x
 	}
@@ -579,6 +669,8 @@ func canonicalString(v interface{}) string {
 		}
 		b.WriteByte('}')
 		return b.String()
+	case *OrderedObject:
+		return canonicalString(x.Map())
 	default:
 		// fallback: for uncommon types, rely on json.Marshal
 		b, _ := json.Marshal(x)
@@ -586,6 +678,104 @@ func canonicalString(v interface{}) string {
 	}
 }
 
+// numberToFloat64 reports whether v is any Go numeric type (or json.Number)
+// and returns it as float64.
+func numberToFloat64(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int8:
+		return float64(x), true
+	case int16:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint8:
+		return float64(x), true
+	case uint16:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	case *big.Int:
+		if x == nil {
+			return 0, false
+		}
+		f, _ := new(big.Float).SetInt(x).Float64()
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
+// jvEqual compares two values structurally, treating numbers of different
+// Go types as equal when they have the same numeric value.
+func jvEqual(a, b interface{}) bool {
+	if fa, ok := numberToFloat64(a); ok {
+		fb, ok := numberToFloat64(b)
+		if ok && fa == fb && math.Abs(fa) >= 1<<53 {
+			// Large integers may have been rounded; compare them exactly.
+			if ai, ok := jvToBigInt(a); ok {
+				if bi, ok := jvToBigInt(b); ok {
+					return ai.Cmp(bi) == 0
+				}
+			}
+		}
+		return ok && fa == fb
+	}
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case undefinedValue:
+		_, ok := b.(undefinedValue)
+		return ok
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jvEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case *OrderedObject:
+		return jvEqual(x.Map(), b)
+	case map[string]interface{}:
+		y, ok := jvObject(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !jvEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 // Clone creates a deep copy of the JSON value.
 func (j JSON) Clone() JSON {
 	return NewJSON(deepCopy(j.Value))
@@ -606,6 +796,12 @@ func (j JSON) IsNull() bool {
 	return j.Value == nil
 }
 
+// IsUndefined checks if the JSON value represents a missing value.
+func (j JSON) IsUndefined() bool {
+	_, ok := j.Value.(undefinedValue)
+	return ok
+}
+
 // IsObject checks if the JSON value is an object.
 func (j JSON) IsObject() bool {
 	_, ok := j.AsObject()
@@ -637,9 +833,9 @@ func (j JSON) IsBool() bool {
 }
 
 // AsObject returns the JSON value as a map if it's an object.
+// For an ordered object the map backing it is returned.
 func (j JSON) AsObject() (map[string]interface{}, bool) {
-	val, ok := j.Value.(map[string]interface{})
-	return val, ok
+	return jvObject(j.Value)
 }
 
 // AsArray returns the JSON value as a slice if it's an array.
@@ -666,9 +862,9 @@ func (j JSON) AsArrayString() ([]string, bool) {
 	return out, true
 }
 
-// AddToArray adds an element to the JSON array.
+// AddToArray adds an element to the JSON array. An undefined element is not added.
 func (j *JSON) AddToArray(jElem JSON) {
-	if jv, ok := j.Value.([]interface{}); ok {
+	if jv, ok := j.Value.([]interface{}); ok && !jElem.IsUndefined() {
 		jvAddValueToArray(&jv, jElem.Value)
 		j.Value = jv
 	}
@@ -692,13 +888,10 @@ func (j JSON) ArrayElement(num int) JSON {
 	return NewJSONNull()
 }
 
-// ObjectKeys returns all keys of the JSON object.
+// ObjectKeys returns all keys of the JSON object: in insertion order for
+// ordered objects and sorted otherwise.
 func (j JSON) ObjectKeys() []string {
-	if object, ok := j.AsObject(); ok {
-		var keys []string
-		for key := range object {
-			keys = append(keys, key)
-		}
+	if keys := jvObjectKeys(j.Value); keys != nil {
 		return keys
 	}
 	return []string{}
@@ -720,27 +913,10 @@ func (j JSON) IsNonEmptyArray() bool {
 }
 
 // AsNumeric returns the JSON value as a float64 if it's numeric.
+// Values parsed with NumberJSONNumber or NumberTyped are converted as well;
+// use AsInt64, AsUint64 or AsBigInt to read large integers exactly.
 func (j JSON) AsNumeric() (float64, bool) {
-	switch j.Value.(type) {
-	case float64:
-		return float64(j.Value.(float64)), true
-	case float32:
-		return float64(j.Value.(float32)), true
-	case int:
-		return float64(j.Value.(int)), true
-	case int64:
-		return float64(j.Value.(int64)), true
-	case uint:
-		return float64(j.Value.(uint)), true
-	case uint64:
-		return float64(j.Value.(uint64)), true
-	case uint32:
-		return float64(j.Value.(uint32)), true
-	case byte:
-		return float64(j.Value.(byte)), true
-	default:
-		return 0.0, false
-	}
+	return numberToFloat64(j.Value)
 }
 
 // AsString returns the JSON value as a string if it's a string.
@@ -753,17 +929,11 @@ func (j JSON) AsString() (string, bool) {
 	}
 }
 
-// AsBytes returns the JSON value as bytes if it's a hex-encoded string.
+// AsBytes returns the JSON value as bytes if it's a string in the default
+// byte encoding. Use AsBytesWith to choose the encoding and get an error.
 func (j JSON) AsBytes() ([]byte, bool) {
-	switch j.Value.(type) {
-	case string:
-		if b, err := hex.DecodeString(j.Value.(string)); err == nil {
-			return b, true
-		}
-		return nil, false
-	default:
-		return nil, false
-	}
+	b, err := j.AsBytesWith(DefaultByteEncoding())
+	return b, err == nil
 }
 
 // AsBool returns the JSON value as a boolean if it's a boolean.
@@ -872,10 +1042,10 @@ func (b *JSONBuilder) SetIfNotEmpty(path string, value interface{}) *JSONBuilder
 
 // AddToArray adds value to array at path
 func (b *JSONBuilder) AddToArray(path string, value interface{}) *JSONBuilder {
-	if !b.json.PathExists(path) {
-		b.json.SetByPath(path, NewJSONArray())
+	arr, ok := b.json.Lookup(path)
+	if !ok {
+		arr = NewJSONArray()
 	}
-	arr := b.json.GetByPath(path)
 	arr.AddToArray(NewJSON(value))
 	b.json.SetByPath(path, arr)
 	return b
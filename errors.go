@@ -0,0 +1,57 @@
+package easyjson
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors wrapped by *PathError. Use errors.Is to test for them.
+var (
+	// ErrPathNotFound means an object member on the path does not exist.
+	ErrPathNotFound = errors.New("path not found")
+	// ErrIndexOutOfRange means an array index on the path is outside the array.
+	ErrIndexOutOfRange = errors.New("array index out of range")
+	// ErrNotContainer means the path continues below a value that is neither an object nor an array.
+	ErrNotContainer = errors.New("value is not an object or array")
+	// ErrInvalidIndex means a path segment addressing an array is not an integer.
+	ErrInvalidIndex = errors.New("invalid array index")
+	// ErrInvalidPath means the path or one of its segments is empty.
+	ErrInvalidPath = errors.New("invalid path")
+	// ErrPathConflict means Unflatten got a path whose location an earlier path already set.
+	ErrPathConflict = errors.New("path already set")
+)
+
+// Errors wrapped by *ParseError when ParseOptions safeguards reject the input.
+var (
+	// ErrLimitExceeded means the input exceeds one of the ParseOptions limits.
+	ErrLimitExceeded = errors.New("parse limit exceeded")
+	// ErrDuplicateKey means an object repeats a key under DuplicateKeyError.
+	ErrDuplicateKey = errors.New("duplicate object key")
+)
+
+// ErrNotBytes is wrapped by the errors of AsBytesWith when a value is not a
+// string holding bytes in the requested ByteEncoding.
+var ErrNotBytes = errors.New("value is not an encoded byte string")
+
+// PathError describes why a path operation failed.
+// Segment is the path segment at which the operation stopped, SegmentIndex
+// its zero-based position among the segments and Offset its byte offset in Path.
+type PathError struct {
+	Path         string
+	Segment      string
+	SegmentIndex int
+	Offset       int
+	Err          error
+}
+
+func newPathError(path, segment string, segmentIndex, offset int, err error) *PathError {
+	return &PathError{Path: path, Segment: segment, SegmentIndex: segmentIndex, Offset: offset, Err: err}
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("easyjson: path %q: segment %q (#%d at offset %d): %v", e.Path, e.Segment, e.SegmentIndex, e.Offset, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
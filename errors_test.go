@@ -0,0 +1,90 @@
+package easyjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func assertPathError(t *testing.T, err error, target error, segment string, segmentIndex int) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("expected %v, got %v", target, err)
+	}
+	var perr *PathError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *PathError, got %T", err)
+	}
+	if perr.Segment != segment || perr.SegmentIndex != segmentIndex {
+		t.Fatalf("expected segment %q #%d, got %q #%d", segment, segmentIndex, perr.Segment, perr.SegmentIndex)
+	}
+}
+
+func TestTryGetByPath(t *testing.T) {
+	j := mustJSONFromString(t, `{"a":{"b":[1,{"c":"x"}]},"n":null}`)
+
+	v, err := j.TryGetByPath("a.b.1.c")
+	if err != nil || v.AsStringDefault("") != "x" {
+		t.Fatalf("TryGetByPath failed: %v %v", v.Value, err)
+	}
+	if v, err := j.TryGetByPath("n"); err != nil || !v.IsNull() {
+		t.Fatalf("explicit null must be found: %v", err)
+	}
+
+	_, err = j.TryGetByPath("a.x.y")
+	assertPathError(t, err, ErrPathNotFound, "x", 1)
+
+	_, err = j.TryGetByPath("a.b.5")
+	assertPathError(t, err, ErrIndexOutOfRange, "5", 2)
+
+	_, err = j.TryGetByPath("a.b.first")
+	assertPathError(t, err, ErrInvalidIndex, "first", 2)
+
+	_, err = j.TryGetByPath("a.b.0.c")
+	assertPathError(t, err, ErrNotContainer, "c", 3)
+
+	var perr *PathError
+	errors.As(err, &perr)
+	if perr.Offset != len("a.b.0.") {
+		t.Fatalf("unexpected offset %d", perr.Offset)
+	}
+}
+
+func TestTrySetByPath(t *testing.T) {
+	j := mustJSONFromString(t, `{"s":"str","arr":[1]}`)
+
+	if err := j.TrySetByPath("x.y", NewJSON(1)); err != nil {
+		t.Fatalf("TrySetByPath failed: %v", err)
+	}
+	assertPathError(t, j.TrySetByPath("s.inner", NewJSON(1)), ErrNotContainer, "inner", 1)
+	assertPathError(t, j.TrySetByPath("arr.k", NewJSON(1)), ErrInvalidIndex, "k", 1)
+	assertPathError(t, j.TrySetByPath("arr.3.k", NewJSON(1)), ErrIndexOutOfRange, "3", 1)
+	if err := j.TrySetByPath("", NewJSON(1)); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("expected ErrInvalidPath, got %v", err)
+	}
+}
+
+func TestTryRemoveByPath(t *testing.T) {
+	j := mustJSONFromString(t, `{"a":{"b":1},"arr":[1]}`)
+
+	if err := j.TryRemoveByPath("a.b"); err != nil {
+		t.Fatalf("TryRemoveByPath failed: %v", err)
+	}
+	assertPathError(t, j.TryRemoveByPath("a.b"), ErrPathNotFound, "b", 1)
+	assertPathError(t, j.TryRemoveByPath("arr.4"), ErrIndexOutOfRange, "4", 1)
+
+	// The bool variant keeps treating a missing member as already removed.
+	if !j.RemoveByPath("a.b") {
+		t.Fatalf("RemoveByPath of a missing member must succeed")
+	}
+	if j.RemoveByPath("arr.4") {
+		t.Fatalf("RemoveByPath out of range must fail")
+	}
+}
+
+func TestGetAs_PathError(t *testing.T) {
+	j := mustJSONFromString(t, `{"a":[1]}`)
+	_, err := GetAs[int](j, "a.2")
+	if !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+}
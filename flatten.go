@@ -0,0 +1,271 @@
+package easyjson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PathValue is one leaf of a flattened document.
+type PathValue struct {
+	Path  string
+	Value JSON
+}
+
+// Flatten returns the leaves of the document as path/value pairs in document
+// order: object members in the order of ObjectKeys, array elements by index.
+// Leaves are scalars and empty objects or arrays; a document that is itself a
+// leaf yields a single pair with an empty path.
+//
+// Paths use the dot path syntax of GetByPath with the given delimiter
+// (default "."). A backslash escapes the delimiter and itself inside a key,
+// and an object key that looks like an array index is prefixed with a
+// backslash, so Unflatten can tell it from an index. An empty key is an empty
+// segment, except at the end of a path where it is written as a lone
+// backslash, so that {"":1} does not flatten to the root path. Paths without
+// escapes or empty keys are valid GetByPath paths.
+func (j JSON) Flatten(delimiter ...string) []PathValue {
+	f := flattener{delim: pathDelimiter(delimiter...)}
+	f.flatten(nil, j.Value)
+	return f.pairs
+}
+
+type flattener struct {
+	delim byte
+	pairs []PathValue
+}
+
+func (f *flattener) flatten(path []string, v interface{}) {
+	if _, ok := jvObject(v); ok {
+		keys := jvObjectKeys(v)
+		if len(keys) > 0 {
+			for _, k := range keys {
+				cv, _ := jvObjectField(v, k)
+				f.flatten(append(path, escapeFlatKey(k, f.delim)), cv)
+			}
+			return
+		}
+	} else if a, ok := v.([]interface{}); ok && len(a) > 0 {
+		for i, e := range a {
+			f.flatten(append(path, strconv.Itoa(i)), e)
+		}
+		return
+	}
+	p := strings.Join(path, string(f.delim))
+	if n := len(path); n > 0 && path[n-1] == "" {
+		p += `\`
+	}
+	f.pairs = append(f.pairs, PathValue{Path: p, Value: NewJSON(v)})
+}
+
+func escapeFlatKey(k string, delim byte) string {
+	if isFlatIndex(k) {
+		return `\` + k
+	}
+	if strings.IndexByte(k, delim) < 0 && strings.IndexByte(k, '\\') < 0 {
+		return k
+	}
+	var b strings.Builder
+	for i := 0; i < len(k); i++ {
+		if k[i] == delim || k[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(k[i])
+	}
+	return b.String()
+}
+
+// isFlatIndex reports whether s is an array index as written by Flatten.
+func isFlatIndex(s string) bool {
+	if s == "" || len(s) > 1 && s[0] == '0' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Unflatten rebuilds a document from path/value pairs as produced by
+// Flatten. Unlike SetByPath, a segment made of unescaped digits creates an
+// array where no container exists yet, and missing elements before an index
+// are filled with null, so sparse pairs such as the changed leaves of a Diff
+// can be imported. To bound memory use, at most 65536 plus 16 per pair such
+// missing elements are filled in over the whole call. Pairs with an undefined
+// value are skipped. An empty path sets the whole document; no pairs give an
+// empty object. Values are copied.
+//
+// A *PathError is returned if a path is malformed, descends below a scalar,
+// indexes an array with a key, needs more missing elements than allowed
+// (ErrIndexOutOfRange), or sets a location that an earlier pair has already
+// set.
+func Unflatten(pairs []PathValue, delimiter ...string) (JSON, error) {
+	delim := pathDelimiter(delimiter...)
+	if len(pairs) == 0 {
+		return NewJSONObject(), nil
+	}
+	u := unflattener{delim: delim, holes: flatHoleBase + flatHolesPerPair*len(pairs)}
+	var root interface{}
+	set := false
+	for _, pv := range pairs {
+		if pv.Value.IsUndefined() {
+			continue
+		}
+		segs, err := u.split(pv.Path)
+		if err != nil {
+			return NewJSONNull(), err
+		}
+		if root, err = u.insert(root, set, pv.Path, segs, deepCopy(pv.Value.Value)); err != nil {
+			return NewJSONNull(), err
+		}
+		set = true
+	}
+	fillHoles(root)
+	return NewJSON(root), nil
+}
+
+type flatSegment struct {
+	key    string
+	index  bool
+	offset int
+}
+
+// Unflatten may fill in flatHoleBase plus flatHolesPerPair missing array
+// elements per pair, so that a short path with a large index cannot make it
+// allocate without bound.
+const (
+	flatHoleBase     = 65536
+	flatHolesPerPair = 16
+)
+
+type unflattener struct {
+	delim byte
+	holes int // missing array elements that may still be filled in
+}
+
+// split breaks p into its unescaped segments.
+func (u *unflattener) split(p string) ([]flatSegment, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if u.delim == '\\' {
+		return nil, newPathError(p, p, 0, 0, ErrInvalidPath)
+	}
+	var segs []flatSegment
+	var b strings.Builder
+	start, escaped := 0, false
+	for i := 0; i <= len(p); i++ {
+		if i == len(p) || p[i] == u.delim {
+			key := b.String()
+			segs = append(segs, flatSegment{key: key, index: !escaped && isFlatIndex(key), offset: start})
+			b.Reset()
+			start, escaped = i+1, false
+			continue
+		}
+		if p[i] == '\\' {
+			if i+1 == len(p) {
+				if i == start {
+					escaped = true // a lone backslash ending the path is an empty key
+					continue
+				}
+				return nil, newPathError(p, p[start:], len(segs), start, ErrInvalidPath)
+			}
+			i++
+			escaped = true
+		}
+		b.WriteByte(p[i])
+	}
+	return segs, nil
+}
+
+// insert sets v below cur at segs and returns the possibly new cur. exists
+// reports whether cur is an already set location.
+func (u *unflattener) insert(cur interface{}, exists bool, p string, segs []flatSegment, v interface{}) (interface{}, error) {
+	if len(segs) == 0 {
+		if exists {
+			return cur, newPathError(p, "", 0, 0, ErrPathConflict)
+		}
+		return v, nil
+	}
+	var walk func(cur interface{}, exists bool, i int) (interface{}, error)
+	walk = func(cur interface{}, exists bool, i int) (interface{}, error) {
+		s := segs[i]
+		last := i == len(segs)-1
+		if !exists {
+			if s.index {
+				cur = []interface{}{}
+			} else {
+				cur = map[string]interface{}{}
+			}
+		}
+		if _, ok := jvObject(cur); ok {
+			child, found := jvObjectField(cur, s.key)
+			if last {
+				if found {
+					return cur, newPathError(p, s.key, i, s.offset, ErrPathConflict)
+				}
+				jvObjectSet(cur, s.key, v)
+				return cur, nil
+			}
+			child, err := walk(child, found, i+1)
+			if err != nil {
+				return cur, err
+			}
+			jvObjectSet(cur, s.key, child)
+			return cur, nil
+		}
+		a, ok := cur.([]interface{})
+		if !ok {
+			return cur, newPathError(p, s.key, i, s.offset, ErrNotContainer)
+		}
+		if !s.index {
+			return cur, newPathError(p, s.key, i, s.offset, ErrInvalidIndex)
+		}
+		idx, err := strconv.Atoi(s.key)
+		if err != nil || idx-len(a) > u.holes {
+			return cur, newPathError(p, s.key, i, s.offset, ErrIndexOutOfRange)
+		}
+		if idx > len(a) {
+			u.holes -= idx - len(a)
+		}
+		found := idx < len(a) && a[idx] != flatHole{}
+		for len(a) <= idx {
+			a = append(a, flatHole{})
+		}
+		if last {
+			if found {
+				return a, newPathError(p, s.key, i, s.offset, ErrPathConflict)
+			}
+			a[idx] = v
+			return a, nil
+		}
+		child, err := walk(a[idx], found, i+1)
+		a[idx] = child
+		return a, err
+	}
+	return walk(cur, exists, 0)
+}
+
+// flatHole marks array elements that no pair has set yet.
+type flatHole struct{}
+
+// fillHoles replaces the flatHole elements left by insert with null.
+func fillHoles(v interface{}) {
+	if _, ok := jvObject(v); ok {
+		for _, k := range jvObjectKeys(v) {
+			cv, _ := jvObjectField(v, k)
+			fillHoles(cv)
+		}
+		return
+	}
+	if a, ok := v.([]interface{}); ok {
+		for i, e := range a {
+			if _, ok := e.(flatHole); ok {
+				a[i] = nil
+			} else {
+				fillHoles(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package easyjson
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	j, _ := JSONFromString(`{"a":{"b":[{"c":1},2]},"x.y":true,"0":"zero","back\\slash":null,"e":{},"f":[]}`)
+	want := []struct {
+		path  string
+		value string
+	}{
+		{`\0`, `"zero"`}, {`a.b.0.c`, `1`}, {`a.b.1`, `2`}, {`back\\slash`, `null`},
+		{`e`, `{}`}, {`f`, `[]`}, {`x\.y`, `true`},
+	}
+	got := j.Flatten()
+	if len(got) != len(want) {
+		t.Fatalf("got %d pairs: %v", len(got), got)
+	}
+	for i, w := range want {
+		if got[i].Path != w.path || got[i].Value.ToString() != w.value {
+			t.Errorf("pair %d: got %s=%s, want %s=%s", i, got[i].Path, got[i].Value.ToString(), w.path, w.value)
+		}
+	}
+	if v := j.GetByPath(got[1].Path); v.ToString() != "1" {
+		t.Errorf("flattened paths must be GetByPath paths: %s", v.ToString())
+	}
+
+	o := NewJSONOrderedObject()
+	o.SetByPath("z", NewJSON(1.0))
+	o.SetByPath("a/b", NewJSON(2.0))
+	if got := o.Flatten("/"); len(got) != 2 || got[0].Path != "z" || got[1].Path != `a\/b` {
+		t.Errorf("ordered object with custom delimiter: %v", got)
+	}
+	if got := NewJSON(map[string]interface{}{"": 1.0}).Flatten(); len(got) != 1 || got[0].Path != `\` {
+		t.Errorf("top-level empty key: %v", got)
+	}
+	if got := mustJSONFromString(t, `{"":{"x":{"":1}}}`).Flatten("/"); len(got) != 1 || got[0].Path != `/x/\` {
+		t.Errorf("nested empty keys: %v", got)
+	}
+	if got := NewJSON("s").Flatten(); len(got) != 1 || got[0].Path != "" || got[0].Value.Value != "s" {
+		t.Errorf("scalar document: %v", got)
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	for _, doc := range []string{
+		`{"a":{"b":[{"c":1},2]},"x.y":true,"0":"zero","back\\slash":null,"e":{},"f":[],"g":{"":1}}`,
+		`[[1,[2]],{"10":[]}]`, `"s"`, `{}`,
+		`{"":1}`, `{"":{"":[{"":null}]},"a":{"":{}}}`, `[{"":1}]`,
+	} {
+		j, _ := JSONFromString(doc)
+		back, err := Unflatten(j.Flatten())
+		if err != nil || !back.Equals(j) {
+			t.Errorf("%s: round trip gave %s, %v", doc, back.ToString(), err)
+		}
+	}
+
+	j, err := Unflatten([]PathValue{
+		{"list|2", NewJSON("c")}, {"list|0", NewJSON("a")}, {`obj|\1`, NewJSON(true)},
+	}, "|")
+	if err != nil || j.ToString() != `{"list":["a",null,"c"],"obj":{"1":true}}` {
+		t.Fatalf("got %s, %v", j.ToString(), err)
+	}
+	if j, err := Unflatten([]PathValue{{"a.5", NewJSON(1.0)}}); err != nil || j.ToString() != `{"a":[null,null,null,null,null,1]}` {
+		t.Fatalf("sparse index: got %s, %v", j.ToString(), err)
+	}
+	if j, err := Unflatten([]PathValue{{"a.65552", NewJSON(true)}}); err != nil || j.GetByPath("a").ArraySize() != 65553 {
+		t.Fatalf("index within the budget: %v", err)
+	}
+	if j, err := Unflatten([]PathValue{{"a.0", NewJSONUndefined()}, {"b", NewJSON(1.0)}}); err != nil || j.ToString() != `{"b":1}` {
+		t.Fatalf("undefined values must be skipped: %s %v", j.ToString(), err)
+	}
+
+	// Many short pairs with large indexes must not add up to a large allocation.
+	var sparse []PathValue
+	for i := 0; i < 1000; i++ {
+		sparse = append(sparse, PathValue{Path: "k" + strconv.Itoa(i) + ".60000", Value: NewJSON(1.0)})
+	}
+	if _, err := Unflatten(sparse); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected the hole budget to be exhausted, got %v", err)
+	}
+	if j, _ := Unflatten(nil); j.ToString() != `{}` {
+		t.Fatalf("no pairs: %s", j.ToString())
+	}
+
+	for _, c := range []struct {
+		pairs []PathValue
+		err   error
+	}{
+		{[]PathValue{{"a", NewJSON(1.0)}, {"a", NewJSON(2.0)}}, ErrPathConflict},
+		{[]PathValue{{"a.b", NewJSON(1.0)}, {"a", NewJSON(2.0)}}, ErrPathConflict},
+		{[]PathValue{{"a.0", NewJSON(1.0)}, {"a.0", NewJSON(2.0)}}, ErrPathConflict},
+		{[]PathValue{{"a", NewJSON(1.0)}, {"", NewJSON(2.0)}}, ErrPathConflict},
+		{[]PathValue{{"a", NewJSON(1.0)}, {"a.b", NewJSON(2.0)}}, ErrNotContainer},
+		{[]PathValue{{"a.0", NewJSON(1.0)}, {"a.b", NewJSON(2.0)}}, ErrInvalidIndex},
+		{[]PathValue{{"a.65553", NewJSON(1.0)}}, ErrIndexOutOfRange},
+		{[]PathValue{{"a.0", NewJSON(1.0)}, {"a.65570", NewJSON(1.0)}}, ErrIndexOutOfRange},
+		{[]PathValue{{"a.99999999999999999999", NewJSON(1.0)}}, ErrIndexOutOfRange},
+		{[]PathValue{{`a\`, NewJSON(1.0)}}, ErrInvalidPath},
+	} {
+		_, err := Unflatten(c.pairs)
+		var pe *PathError
+		if !errors.Is(err, c.err) || !errors.As(err, &pe) {
+			t.Errorf("%v: got %v, want %v", c.pairs, err, c.err)
+		}
+	}
+}
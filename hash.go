@@ -0,0 +1,116 @@
+package easyjson
+
+import (
+	"crypto/sha256"
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// HashOptions configures Hash, Fingerprint and MerkleHashes.
+type HashOptions struct {
+	// Normalize hashes the Normalize()d value, so that array order and the
+	// Go number type do not affect the result.
+	Normalize bool
+}
+
+func hashInput(j JSON, opts []HashOptions) JSON {
+	if len(opts) > 0 && opts[0].Normalize {
+		return NewJSON(normalizeValue(j.Value))
+	}
+	return j
+}
+
+// Hash returns the SHA-256 digest of the canonical (RFC 8785) form of the
+// value. Equal documents hash equally regardless of key order or whether
+// numbers are stored as ints or floats, and the digest matches what any
+// other JCS implementation computes.
+func (j JSON) Hash(opts ...HashOptions) ([sha256.Size]byte, error) {
+	b, err := hashInput(j, opts).ToCanonicalBytes()
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(b), nil
+}
+
+// Fingerprint returns a fast non-cryptographic 64-bit hash (FNV-1a) of the
+// canonical form of the value, suitable for deduplication and hash tables.
+func (j JSON) Fingerprint(opts ...HashOptions) (uint64, error) {
+	b, err := hashInput(j, opts).ToCanonicalBytes()
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64(), nil
+}
+
+// MerkleHashes returns a SHA-256 hash for every subtree of the value, keyed
+// by its JSON Pointer ("" is the root). A container's hash is computed from
+// its keys and the hashes of its children, so a change anywhere changes the
+// hash of every enclosing container. Comparing the maps of two versions of a
+// document path by path shows which subtrees changed. Every call hashes the
+// whole tree.
+//
+// The root entry differs from Hash, which digests the canonical bytes.
+func (j JSON) MerkleHashes(opts ...HashOptions) (map[string][sha256.Size]byte, error) {
+	res := map[string][sha256.Size]byte{}
+	if _, err := merkleHash(hashInput(j, opts).Value, nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Node type tags keep leaves and containers with the same content apart.
+const (
+	merkleLeaf byte = iota
+	merkleObject
+	merkleArray
+)
+
+func merkleHash(v interface{}, tokens []string, res map[string][sha256.Size]byte) ([sha256.Size]byte, error) {
+	h := sha256.New()
+	switch x := v.(type) {
+	case *OrderedObject:
+		return merkleHash(x.Map(), tokens, res)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(a, b int) bool { return utf16Less(keys[a], keys[b]) })
+		h.Write([]byte{merkleObject})
+		for _, k := range keys {
+			child, err := merkleHash(x[k], append(tokens[:len(tokens):len(tokens)], k), res)
+			if err != nil {
+				return [sha256.Size]byte{}, err
+			}
+			kb, err := NewJSON(k).ToCanonicalBytes()
+			if err != nil {
+				return [sha256.Size]byte{}, err
+			}
+			h.Write(kb)
+			h.Write(child[:])
+		}
+	case []interface{}:
+		h.Write([]byte{merkleArray})
+		for i, e := range x {
+			child, err := merkleHash(e, append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i)), res)
+			if err != nil {
+				return [sha256.Size]byte{}, err
+			}
+			h.Write(child[:])
+		}
+	default:
+		b, err := NewJSON(v).ToCanonicalBytes()
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		h.Write([]byte{merkleLeaf})
+		h.Write(b)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	res[BuildPointer(tokens...)] = sum
+	return sum, nil
+}
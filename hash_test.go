@@ -0,0 +1,92 @@
+package easyjson
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	a := mustJSONFromString(t, `{"b":[1,2],"a":{"x":1.0}}`)
+	b := NewJSON(map[string]interface{}{
+		"a": map[string]interface{}{"x": int64(1)},
+		"b": []interface{}{1, 2},
+	})
+	ha, err := a.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	hb, _ := b.Hash()
+	if ha != hb {
+		t.Fatalf("equal documents must hash equally")
+	}
+	canon, _ := a.ToCanonicalBytes()
+	if ha != sha256.Sum256(canon) {
+		t.Fatalf("Hash must digest the canonical form")
+	}
+
+	fa, _ := a.Fingerprint()
+	fb, _ := b.Fingerprint()
+	if fa != fb {
+		t.Fatalf("equal documents must have equal fingerprints")
+	}
+
+	c := mustJSONFromString(t, `{"b":[2,1],"a":{"x":1}}`)
+	if hc, _ := c.Hash(); hc == ha {
+		t.Fatalf("array order must matter by default")
+	}
+	hn, _ := a.Hash(HashOptions{Normalize: true})
+	hcn, _ := c.Hash(HashOptions{Normalize: true})
+	if hn != hcn {
+		t.Fatalf("normalized hashes must ignore array order")
+	}
+	fc, _ := c.Fingerprint()
+	fcn, _ := c.Fingerprint(HashOptions{Normalize: true})
+	if fc == fcn || fcn != fa {
+		t.Fatalf("normalized fingerprint must ignore array order")
+	}
+	if !c.Equals(mustJSONFromString(t, `{"b":[2,1],"a":{"x":1}}`)) {
+		t.Fatalf("Normalize option must not modify the value")
+	}
+}
+
+func TestMerkleHashes(t *testing.T) {
+	a := mustJSONFromString(t, `{"cfg":{"a/b":1,"list":[1,2]},"name":"x"}`)
+	b := a.Clone()
+	b.SetByPath("cfg.list.1", NewJSON(3))
+
+	ha, err := a.MerkleHashes()
+	if err != nil {
+		t.Fatalf("MerkleHashes failed: %v", err)
+	}
+	hb, _ := b.MerkleHashes()
+
+	for _, p := range []string{"", "/cfg", "/cfg/a~1b", "/cfg/list", "/cfg/list/0", "/cfg/list/1", "/name"} {
+		if _, ok := ha[p]; !ok {
+			t.Fatalf("missing subtree hash for %q", p)
+		}
+	}
+	var changed []string
+	for _, p := range sortedHashKeys(ha) {
+		if ha[p] != hb[p] {
+			changed = append(changed, p)
+		}
+	}
+	if len(changed) != 4 || changed[0] != "" || changed[1] != "/cfg" || changed[2] != "/cfg/list" || changed[3] != "/cfg/list/1" {
+		t.Fatalf("unexpected changed subtrees: %v", changed)
+	}
+
+	// A leaf and a container with the same canonical text must differ.
+	l, _ := NewJSON("[]").MerkleHashes()
+	e, _ := NewJSONArray().MerkleHashes()
+	if l[""] == e[""] {
+		t.Fatalf("leaf and container hashes must not collide")
+	}
+}
+
+func sortedHashKeys(m map[string][sha256.Size]byte) []string {
+	obj := make(map[string]interface{}, len(m))
+	for k := range m {
+		obj[k] = nil
+	}
+	return sortedKeys(obj)
+}
@@ -0,0 +1,1112 @@
+package easyjson
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// RFC 9535 JSONPath query support.
+//
+// A query starts at the root identifier "$" and is followed by segments:
+//   - child segments: .name, .*, [selectors]
+//   - descendant segments: ..name, ..*, ..[selectors]
+//
+// Bracketed selectors are comma separated and may be names ('a' or "a"),
+// the wildcard *, array indexes (negative counts from the end), slices
+// (start:end:step) and filters ?<expr>. Filter expressions support
+// ||, &&, !, parentheses, the comparison operators == != < <= > >=,
+// literals, relative (@) and absolute ($) queries and the functions
+// length(), count(), match(), search() and value().
+//
+// Object members are visited in sorted key order so results are deterministic.
+
+// JSONPathMatch is a single node selected by a JSONPath query.
+type JSONPathMatch struct {
+	// Path is the normalized path of the node, e.g. $['store']['book'][0].
+	Path  string
+	Value JSON
+
+	location []interface{}
+}
+
+// Pointer returns the location of the matched node as a JSON Pointer.
+func (m JSONPathMatch) Pointer() string {
+	tokens := make([]string, len(m.location))
+	for i, l := range m.location {
+		switch x := l.(type) {
+		case string:
+			tokens[i] = x
+		case int:
+			tokens[i] = strconv.Itoa(x)
+		}
+	}
+	return BuildPointer(tokens...)
+}
+
+// JSONPathError describes a syntax error in a JSONPath expression.
+type JSONPathError struct {
+	Expr   string
+	Offset int
+	Msg    string
+}
+
+func (e *JSONPathError) Error() string {
+	return fmt.Sprintf("jsonpath: %s at offset %d in %q", e.Msg, e.Offset, e.Expr)
+}
+
+// JSONPath is a compiled JSONPath query that can be evaluated many times.
+type JSONPath struct {
+	expr     string
+	segments []jpSegment
+}
+
+// CompileJSONPath parses a JSONPath expression.
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	p := &jpParser{s: expr}
+	segments, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &JSONPath{expr: expr, segments: segments}, nil
+}
+
+// MustCompileJSONPath is like CompileJSONPath but panics if the expression is invalid.
+func MustCompileJSONPath(expr string) *JSONPath {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source expression of the query.
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Query evaluates the compiled query against j.
+func (p *JSONPath) Query(j JSON) []JSONPathMatch {
+	nodes := jpApplySegments(p.segments, []jpNode{{v: j.Value}}, j.Value)
+	res := make([]JSONPathMatch, len(nodes))
+	for i, n := range nodes {
+		res[i] = JSONPathMatch{Path: jpNormalizedPath(n.loc), Value: NewJSON(n.v), location: n.loc}
+	}
+	return res
+}
+
+// Query evaluates the JSONPath expression against j and returns all matched nodes.
+func (j JSON) Query(expr string) ([]JSONPathMatch, error) {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Query(j), nil
+}
+
+// QueryValues evaluates the JSONPath expression against j and returns only the matched values.
+func (j JSON) QueryValues(expr string) ([]JSON, error) {
+	matches, err := j.Query(expr)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]JSON, len(matches))
+	for i, m := range matches {
+		values[i] = m.Value
+	}
+	return values, nil
+}
+
+func jpNormalizedPath(loc []interface{}) string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, l := range loc {
+		switch x := l.(type) {
+		case int:
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(x))
+			b.WriteByte(']')
+		case string:
+			b.WriteString("['")
+			for _, r := range x {
+				switch r {
+				case '\'':
+					b.WriteString(`\'`)
+				case '\\':
+					b.WriteString(`\\`)
+				case '\b':
+					b.WriteString(`\b`)
+				case '\f':
+					b.WriteString(`\f`)
+				case '\n':
+					b.WriteString(`\n`)
+				case '\r':
+					b.WriteString(`\r`)
+				case '\t':
+					b.WriteString(`\t`)
+				default:
+					if r < 0x20 {
+						fmt.Fprintf(&b, `\u%04x`, r)
+					} else {
+						b.WriteRune(r)
+					}
+				}
+			}
+			b.WriteString("']")
+		}
+	}
+	return b.String()
+}
+
+// ------------------------------------
+// Evaluation
+// ------------------------------------
+
+type jpNode struct {
+	loc []interface{}
+	v   interface{}
+}
+
+func (n jpNode) child(key interface{}, v interface{}) jpNode {
+	loc := make([]interface{}, len(n.loc)+1)
+	copy(loc, n.loc)
+	loc[len(n.loc)] = key
+	return jpNode{loc: loc, v: v}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type jpSegment struct {
+	descendant bool
+	selectors  []jpSelector
+}
+
+type jpSelectorKind int
+
+const (
+	jpSelName jpSelectorKind = iota
+	jpSelWildcard
+	jpSelIndex
+	jpSelSlice
+	jpSelFilter
+)
+
+type jpSelector struct {
+	kind   jpSelectorKind
+	name   string
+	index  int
+	slice  [3]*int // start, end, step
+	filter jpExpr
+}
+
+func jpApplySegments(segments []jpSegment, nodes []jpNode, root interface{}) []jpNode {
+	for _, seg := range segments {
+		var out []jpNode
+		for _, n := range nodes {
+			if seg.descendant {
+				jpDescend(n, func(d jpNode) {
+					for _, sel := range seg.selectors {
+						out = sel.apply(d, root, out)
+					}
+				})
+			} else {
+				for _, sel := range seg.selectors {
+					out = sel.apply(n, root, out)
+				}
+			}
+		}
+		nodes = out
+	}
+	return nodes
+}
+
+// jpDescend visits n and all of its descendants in document order.
+func jpDescend(n jpNode, visit func(jpNode)) {
+	visit(n)
+	switch x := n.v.(type) {
+	case map[string]interface{}, *OrderedObject:
+		obj, _ := jvObject(x)
+		for _, k := range jvObjectKeys(x) {
+			jpDescend(n.child(k, obj[k]), visit)
+		}
+	case []interface{}:
+		for i, e := range x {
+			jpDescend(n.child(i, e), visit)
+		}
+	}
+}
+
+func (s jpSelector) apply(n jpNode, root interface{}, out []jpNode) []jpNode {
+	switch s.kind {
+	case jpSelName:
+		if m, ok := jvObject(n.v); ok {
+			if v, ok := m[s.name]; ok {
+				out = append(out, n.child(s.name, v))
+			}
+		}
+	case jpSelWildcard:
+		switch x := n.v.(type) {
+		case map[string]interface{}, *OrderedObject:
+			obj, _ := jvObject(x)
+			for _, k := range jvObjectKeys(x) {
+				out = append(out, n.child(k, obj[k]))
+			}
+		case []interface{}:
+			for i, e := range x {
+				out = append(out, n.child(i, e))
+			}
+		}
+	case jpSelIndex:
+		if a, ok := n.v.([]interface{}); ok {
+			idx := s.index
+			if idx < 0 {
+				idx += len(a)
+			}
+			if idx >= 0 && idx < len(a) {
+				out = append(out, n.child(idx, a[idx]))
+			}
+		}
+	case jpSelSlice:
+		if a, ok := n.v.([]interface{}); ok {
+			for _, idx := range jpSliceIndexes(s.slice, len(a)) {
+				out = append(out, n.child(idx, a[idx]))
+			}
+		}
+	case jpSelFilter:
+		switch x := n.v.(type) {
+		case map[string]interface{}, *OrderedObject:
+			obj, _ := jvObject(x)
+			for _, k := range jvObjectKeys(x) {
+				if s.filter.eval(obj[k], root).truthy() {
+					out = append(out, n.child(k, obj[k]))
+				}
+			}
+		case []interface{}:
+			for i, e := range x {
+				if s.filter.eval(e, root).truthy() {
+					out = append(out, n.child(i, e))
+				}
+			}
+		}
+	}
+	return out
+}
+
+// jpSliceIndexes implements the slice selector semantics of RFC 9535 section 2.3.4.2.
+func jpSliceIndexes(sl [3]*int, length int) []int {
+	step := 1
+	if sl[2] != nil {
+		step = *sl[2]
+	}
+	if step == 0 {
+		return nil
+	}
+	normalize := func(i int) int {
+		if i >= 0 {
+			return i
+		}
+		return length + i
+	}
+	clamp := func(i, lo, hi int) int {
+		if i < lo {
+			return lo
+		}
+		if i > hi {
+			return hi
+		}
+		return i
+	}
+
+	var idxs []int
+	if step > 0 {
+		start, end := 0, length
+		if sl[0] != nil {
+			start = normalize(*sl[0])
+		}
+		if sl[1] != nil {
+			end = normalize(*sl[1])
+		}
+		lower, upper := clamp(start, 0, length), clamp(end, 0, length)
+		for i := lower; i < upper; i += step {
+			idxs = append(idxs, i)
+			if step >= upper-i { // stop before i += step can overflow
+				break
+			}
+		}
+	} else {
+		start, end := length-1, -length-1
+		if sl[0] != nil {
+			start = normalize(*sl[0])
+		}
+		if sl[1] != nil {
+			end = normalize(*sl[1])
+		}
+		upper, lower := clamp(start, -1, length-1), clamp(end, -1, length-1)
+		for i := upper; lower < i; i += step {
+			idxs = append(idxs, i)
+			if step <= lower-i {
+				break
+			}
+		}
+	}
+	return idxs
+}
+
+// ------------------------------------
+// Filter expressions
+// ------------------------------------
+
+type jpValKind int
+
+const (
+	jpNothing jpValKind = iota
+	jpValue
+	jpNodes
+	jpLogical
+)
+
+type jpVal struct {
+	kind  jpValKind
+	v     interface{}
+	nodes []jpNode
+	b     bool
+}
+
+func (v jpVal) truthy() bool {
+	switch v.kind {
+	case jpLogical:
+		return v.b
+	case jpNodes:
+		return len(v.nodes) > 0
+	default:
+		return false
+	}
+}
+
+// value converts the result to a single JSON value; false means Nothing.
+func (v jpVal) value() (interface{}, bool) {
+	switch v.kind {
+	case jpValue:
+		return v.v, true
+	case jpNodes:
+		if len(v.nodes) == 1 {
+			return v.nodes[0].v, true
+		}
+	}
+	return nil, false
+}
+
+type jpExpr interface {
+	eval(cur, root interface{}) jpVal
+}
+
+type jpOr struct{ l, r jpExpr }
+
+func (e jpOr) eval(cur, root interface{}) jpVal {
+	return jpVal{kind: jpLogical, b: e.l.eval(cur, root).truthy() || e.r.eval(cur, root).truthy()}
+}
+
+type jpAnd struct{ l, r jpExpr }
+
+func (e jpAnd) eval(cur, root interface{}) jpVal {
+	return jpVal{kind: jpLogical, b: e.l.eval(cur, root).truthy() && e.r.eval(cur, root).truthy()}
+}
+
+type jpNot struct{ x jpExpr }
+
+func (e jpNot) eval(cur, root interface{}) jpVal {
+	return jpVal{kind: jpLogical, b: !e.x.eval(cur, root).truthy()}
+}
+
+type jpLiteral struct{ v interface{} }
+
+func (e jpLiteral) eval(cur, root interface{}) jpVal {
+	return jpVal{kind: jpValue, v: e.v}
+}
+
+type jpQuery struct {
+	relative bool
+	singular bool
+	segments []jpSegment
+}
+
+func (e jpQuery) eval(cur, root interface{}) jpVal {
+	start := root
+	if e.relative {
+		start = cur
+	}
+	return jpVal{kind: jpNodes, nodes: jpApplySegments(e.segments, []jpNode{{v: start}}, root)}
+}
+
+type jpComparison struct {
+	op   string
+	l, r jpExpr
+}
+
+func (e jpComparison) eval(cur, root interface{}) jpVal {
+	l, lok := e.l.eval(cur, root).value()
+	r, rok := e.r.eval(cur, root).value()
+	var res bool
+	switch e.op {
+	case "==":
+		res = jpEqualOrNothing(l, lok, r, rok)
+	case "!=":
+		res = !jpEqualOrNothing(l, lok, r, rok)
+	case "<":
+		res = lok && rok && jpLess(l, r)
+	case "<=":
+		res = lok && rok && (jpLess(l, r) || jvEqual(l, r))
+	case ">":
+		res = lok && rok && jpLess(r, l)
+	case ">=":
+		res = lok && rok && (jpLess(r, l) || jvEqual(l, r))
+	}
+	return jpVal{kind: jpLogical, b: res}
+}
+
+func jpEqualOrNothing(l interface{}, lok bool, r interface{}, rok bool) bool {
+	if !lok || !rok {
+		return lok == rok
+	}
+	return jvEqual(l, r)
+}
+
+func jpLess(a, b interface{}) bool {
+	if fa, ok := numberToFloat64(a); ok {
+		fb, ok := numberToFloat64(b)
+		return ok && fa < fb
+	}
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		return ok && sa < sb
+	}
+	return false
+}
+
+type jpFunc struct {
+	name string
+	args []jpExpr
+	re   *regexp.Regexp // precompiled pattern when the second argument is a literal
+}
+
+func (e jpFunc) eval(cur, root interface{}) jpVal {
+	switch e.name {
+	case "length":
+		v, ok := e.args[0].eval(cur, root).value()
+		if !ok {
+			return jpVal{kind: jpNothing}
+		}
+		switch x := v.(type) {
+		case string:
+			return jpVal{kind: jpValue, v: float64(utf8.RuneCountInString(x))}
+		case []interface{}:
+			return jpVal{kind: jpValue, v: float64(len(x))}
+		case map[string]interface{}:
+			return jpVal{kind: jpValue, v: float64(len(x))}
+		case *OrderedObject:
+			return jpVal{kind: jpValue, v: float64(x.Len())}
+		}
+		return jpVal{kind: jpNothing}
+	case "count":
+		return jpVal{kind: jpValue, v: float64(len(e.args[0].eval(cur, root).nodes))}
+	case "value":
+		v, ok := e.args[0].eval(cur, root).value()
+		if !ok {
+			return jpVal{kind: jpNothing}
+		}
+		return jpVal{kind: jpValue, v: v}
+	case "match", "search":
+		v, ok := e.args[0].eval(cur, root).value()
+		s, isStr := v.(string)
+		if !ok || !isStr {
+			return jpVal{kind: jpLogical}
+		}
+		re := e.re
+		if re == nil {
+			p, ok := e.args[1].eval(cur, root).value()
+			ps, isStr := p.(string)
+			if !ok || !isStr {
+				return jpVal{kind: jpLogical}
+			}
+			var err error
+			if re, err = jpCompileRegexp(ps, e.name == "match"); err != nil {
+				return jpVal{kind: jpLogical}
+			}
+		}
+		return jpVal{kind: jpLogical, b: re.MatchString(s)}
+	}
+	return jpVal{kind: jpNothing}
+}
+
+func jpCompileRegexp(pattern string, anchored bool) (*regexp.Regexp, error) {
+	if anchored {
+		pattern = `\A(?:` + pattern + `)\z`
+	}
+	return regexp.Compile(pattern)
+}
+
+// ------------------------------------
+// Parser
+// ------------------------------------
+
+type jpParser struct {
+	s   string
+	pos int
+}
+
+func (p *jpParser) errorf(format string, args ...interface{}) error {
+	return &JSONPathError{Expr: p.s, Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *jpParser) skipSpace() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *jpParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *jpParser) consume(tok string) bool {
+	if strings.HasPrefix(p.s[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+func (p *jpParser) parseQuery() ([]jpSegment, error) {
+	if !p.consume("$") {
+		return nil, p.errorf("query must start with $")
+	}
+	segments, _, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.s) {
+		return nil, p.errorf("unexpected character %q", p.s[p.pos])
+	}
+	return segments, nil
+}
+
+// parseSegments parses segments until something that is not a segment is found.
+// The second result reports whether all segments are singular (name or index only).
+func (p *jpParser) parseSegments() ([]jpSegment, bool, error) {
+	var segments []jpSegment
+	singular := true
+	for {
+		save := p.pos
+		p.skipSpace()
+		switch {
+		case p.consume(".."):
+			seg, err := p.parseDescendant()
+			if err != nil {
+				return nil, false, err
+			}
+			segments = append(segments, seg)
+			singular = false
+		case p.consume("."):
+			sel, err := p.parseShorthand()
+			if err != nil {
+				return nil, false, err
+			}
+			segments = append(segments, jpSegment{selectors: []jpSelector{sel}})
+			singular = singular && sel.kind == jpSelName
+		case p.peek() == '[':
+			p.pos++
+			sels, err := p.parseBracket()
+			if err != nil {
+				return nil, false, err
+			}
+			segments = append(segments, jpSegment{selectors: sels})
+			singular = singular && len(sels) == 1 && (sels[0].kind == jpSelName || sels[0].kind == jpSelIndex)
+		default:
+			p.pos = save
+			return segments, singular, nil
+		}
+	}
+}
+
+func (p *jpParser) parseDescendant() (jpSegment, error) {
+	if p.peek() == '[' {
+		p.pos++
+		sels, err := p.parseBracket()
+		if err != nil {
+			return jpSegment{}, err
+		}
+		return jpSegment{descendant: true, selectors: sels}, nil
+	}
+	sel, err := p.parseShorthand()
+	if err != nil {
+		return jpSegment{}, err
+	}
+	return jpSegment{descendant: true, selectors: []jpSelector{sel}}, nil
+}
+
+// parseShorthand parses the part after "." or "..": a wildcard or a member name.
+func (p *jpParser) parseShorthand() (jpSelector, error) {
+	if p.consume("*") {
+		return jpSelector{kind: jpSelWildcard}, nil
+	}
+	name := p.parseMemberName()
+	if name == "" {
+		return jpSelector{}, p.errorf("expected member name")
+	}
+	return jpSelector{kind: jpSelName, name: name}, nil
+}
+
+func (p *jpParser) parseMemberName() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		isFirst := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r >= 0x80
+		isDigit := r >= '0' && r <= '9'
+		if !isFirst && !(isDigit && p.pos > start) {
+			break
+		}
+		p.pos += size
+	}
+	return p.s[start:p.pos]
+}
+
+// parseBracket parses comma separated selectors up to and including "]".
+func (p *jpParser) parseBracket() ([]jpSelector, error) {
+	var sels []jpSelector
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.skipSpace()
+		if p.consume("]") {
+			return sels, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+func (p *jpParser) parseSelector() (jpSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return jpSelector{}, err
+		}
+		return jpSelector{kind: jpSelName, name: s}, nil
+	case c == '*':
+		p.pos++
+		return jpSelector{kind: jpSelWildcard}, nil
+	case c == '?':
+		p.pos++
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return jpSelector{}, err
+		}
+		return jpSelector{kind: jpSelFilter, filter: expr}, nil
+	case c == ':' || c == '-' || (c >= '0' && c <= '9'):
+		return p.parseIndexOrSlice()
+	}
+	return jpSelector{}, p.errorf("invalid selector")
+}
+
+func (p *jpParser) parseIndexOrSlice() (jpSelector, error) {
+	var parts [3]*int
+	for i := 0; i < 3; i++ {
+		p.skipSpace()
+		if c := p.peek(); c == '-' || (c >= '0' && c <= '9') {
+			n, err := p.parseInt()
+			if err != nil {
+				return jpSelector{}, err
+			}
+			parts[i] = &n
+			p.skipSpace()
+		}
+		if i == 0 && p.peek() != ':' {
+			if parts[0] == nil {
+				return jpSelector{}, p.errorf("expected index")
+			}
+			return jpSelector{kind: jpSelIndex, index: *parts[0]}, nil
+		}
+		if i < 2 && !p.consume(":") {
+			break
+		}
+	}
+	return jpSelector{kind: jpSelSlice, slice: parts}, nil
+}
+
+func (p *jpParser) parseInt() (int, error) {
+	start := p.pos
+	p.consume("-")
+	digits := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+	lit := p.s[start:p.pos]
+	if p.pos == digits || (p.s[digits] == '0' && (p.pos-digits > 1 || digits > start)) {
+		p.pos = start
+		return 0, p.errorf("invalid integer %q", lit)
+	}
+	n, err := strconv.ParseInt(lit, 10, 64)
+	if err != nil || n > jpMaxInt || n < -jpMaxInt {
+		p.pos = start
+		return 0, p.errorf("integer %q outside the I-JSON range", lit)
+	}
+	return int(n), nil
+}
+
+// jpMaxInt bounds integers in queries to the I-JSON range required by
+// RFC 9535, 2^53-1.
+const jpMaxInt = 1<<53 - 1
+
+func (p *jpParser) parseString() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			p.pos++
+			if p.pos >= len(p.s) {
+				return "", p.errorf("unterminated escape")
+			}
+			e := p.s[p.pos]
+			p.pos++
+			switch e {
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '/', '\\', '\'', '"':
+				if (e == '\'' || e == '"') && e != quote {
+					return "", p.errorf("invalid escape \\%c", e)
+				}
+				b.WriteByte(e)
+			case 'u':
+				r, err := p.parseHex4()
+				if err != nil {
+					return "", err
+				}
+				if utf16.IsSurrogate(r) {
+					if !p.consume(`\u`) {
+						return "", p.errorf("unpaired surrogate")
+					}
+					r2, err := p.parseHex4()
+					if err != nil {
+						return "", err
+					}
+					r = utf16.DecodeRune(r, r2)
+					if r == utf8.RuneError {
+						return "", p.errorf("invalid surrogate pair")
+					}
+				}
+				b.WriteRune(r)
+			default:
+				return "", p.errorf("invalid escape \\%c", e)
+			}
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *jpParser) parseHex4() (rune, error) {
+	if p.pos+4 > len(p.s) {
+		return 0, p.errorf("invalid \\u escape")
+	}
+	n, err := strconv.ParseUint(p.s[p.pos:p.pos+4], 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid \\u escape")
+	}
+	p.pos += 4
+	return rune(n), nil
+}
+
+func (p *jpParser) parseLogicalOr() (jpExpr, error) {
+	l, err := p.parseLogicalAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("||") {
+			return l, nil
+		}
+		r, err := p.parseLogicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = jpOr{l, r}
+	}
+}
+
+func (p *jpParser) parseLogicalAnd() (jpExpr, error) {
+	l, err := p.parseBasic()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("&&") {
+			return l, nil
+		}
+		r, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		l = jpAnd{l, r}
+	}
+}
+
+var jpComparisonOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *jpParser) parseBasic() (jpExpr, error) {
+	p.skipSpace()
+	if p.consume("!") {
+		p.skipSpace()
+		if p.peek() != '(' && p.peek() != '@' && p.peek() != '$' && !p.atFunction() {
+			return nil, p.errorf("expected test expression after !")
+		}
+		x, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		return jpNot{x}, nil
+	}
+	if p.consume("(") {
+		x, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return x, nil
+	}
+
+	start := p.pos
+	l, err := p.parseComparable()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range jpComparisonOps {
+		if !p.consume(op) {
+			continue
+		}
+		if err := p.checkComparable(l, start); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		rstart := p.pos
+		r, err := p.parseComparable()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkComparable(r, rstart); err != nil {
+			return nil, err
+		}
+		return jpComparison{op: op, l: l, r: r}, nil
+	}
+
+	// Not a comparison: must be a test expression.
+	switch x := l.(type) {
+	case jpQuery:
+		return x, nil
+	case jpFunc:
+		if x.name == "match" || x.name == "search" {
+			return x, nil
+		}
+	}
+	p.pos = start
+	return nil, p.errorf("expected test or comparison expression")
+}
+
+// checkComparable enforces that comparison operands produce a single value.
+func (p *jpParser) checkComparable(e jpExpr, at int) error {
+	switch x := e.(type) {
+	case jpQuery:
+		if !x.singular {
+			return &JSONPathError{Expr: p.s, Offset: at, Msg: "non-singular query in comparison"}
+		}
+	case jpFunc:
+		if x.name == "match" || x.name == "search" {
+			return &JSONPathError{Expr: p.s, Offset: at, Msg: x.name + "() result is not comparable"}
+		}
+	}
+	return nil
+}
+
+func (p *jpParser) atFunction() bool {
+	i := p.pos
+	for i < len(p.s) && p.s[i] >= 'a' && p.s[i] <= 'z' {
+		i++
+	}
+	return i > p.pos && i < len(p.s) && p.s[i] == '('
+}
+
+func (p *jpParser) parseComparable() (jpExpr, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, singular, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return jpQuery{relative: c == '@', singular: singular, segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return jpLiteral{s}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case p.atFunction():
+		return p.parseFunction()
+	case c >= 'a' && c <= 'z':
+		return p.parseKeyword()
+	}
+	return nil, p.errorf("expected literal, query or function")
+}
+
+// parseKeyword parses true, false or null, which must end at a word boundary.
+func (p *jpParser) parseKeyword() (jpExpr, error) {
+	start := p.pos
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r >= 0x80) {
+			break
+		}
+		p.pos += size
+	}
+	switch word := p.s[start:p.pos]; word {
+	case "true":
+		return jpLiteral{true}, nil
+	case "false":
+		return jpLiteral{false}, nil
+	case "null":
+		return jpLiteral{nil}, nil
+	default:
+		p.pos = start
+		return nil, p.errorf("invalid literal %q", word)
+	}
+}
+
+func (p *jpParser) parseNumber() (jpExpr, error) {
+	start := p.pos
+	p.consume("-")
+	for p.pos < len(p.s) && strings.IndexByte("0123456789.eE+-", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	return jpLiteral{f}, nil
+}
+
+var jpFunctionArity = map[string]int{
+	"length": 1,
+	"count":  1,
+	"value":  1,
+	"match":  2,
+	"search": 2,
+}
+
+func (p *jpParser) parseFunction() (jpExpr, error) {
+	start := p.pos
+	for p.peek() >= 'a' && p.peek() <= 'z' {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	arity, known := jpFunctionArity[name]
+	if !known {
+		p.pos = start
+		return nil, p.errorf("unknown function %s()", name)
+	}
+	p.pos++ // "("
+
+	fn := jpFunc{name: name}
+	for {
+		p.skipSpace()
+		if len(fn.args) == 0 && p.consume(")") {
+			break
+		}
+		arg, err := p.parseComparable()
+		if err != nil {
+			return nil, err
+		}
+		fn.args = append(fn.args, arg)
+		p.skipSpace()
+		if p.consume(")") {
+			break
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or )")
+		}
+	}
+	if len(fn.args) != arity {
+		return nil, &JSONPathError{Expr: p.s, Offset: start, Msg: fmt.Sprintf("%s() takes %d argument(s)", name, arity)}
+	}
+
+	switch name {
+	case "count", "value":
+		if _, ok := fn.args[0].(jpQuery); !ok {
+			return nil, &JSONPathError{Expr: p.s, Offset: start, Msg: name + "() requires a query argument"}
+		}
+	case "match", "search":
+		if lit, ok := fn.args[1].(jpLiteral); ok {
+			ps, isStr := lit.v.(string)
+			if !isStr {
+				return nil, &JSONPathError{Expr: p.s, Offset: start, Msg: name + "() pattern must be a string"}
+			}
+			re, err := jpCompileRegexp(ps, name == "match")
+			if err != nil {
+				return nil, &JSONPathError{Expr: p.s, Offset: start, Msg: "invalid regular expression: " + err.Error()}
+			}
+			fn.re = re
+		}
+	}
+	return fn, nil
+}
@@ -0,0 +1,228 @@
+package easyjson
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+const jsonPathStore = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	}
+}`
+
+func queryPaths(t *testing.T, j JSON, expr string) []string {
+	t.Helper()
+	matches, err := j.Query(expr)
+	if err != nil {
+		t.Fatalf("%s: %v", expr, err)
+	}
+	paths := make([]string, len(matches))
+	for i, m := range matches {
+		paths[i] = m.Path
+	}
+	return paths
+}
+
+func TestJSONPath_Basics(t *testing.T) {
+	j := mustJSONFromString(t, jsonPathStore)
+
+	cases := map[string][]string{
+		"$.store.book[*].author": {
+			"$['store']['book'][0]['author']",
+			"$['store']['book'][1]['author']",
+			"$['store']['book'][2]['author']",
+			"$['store']['book'][3]['author']",
+		},
+		"$.store.book[-1].title":  {"$['store']['book'][3]['title']"},
+		"$.store.book[0,1].price": {"$['store']['book'][0]['price']", "$['store']['book'][1]['price']"},
+		"$.store.book[:2].price":  {"$['store']['book'][0]['price']", "$['store']['book'][1]['price']"},
+		"$.store.book[::-2].price": {
+			"$['store']['book'][3]['price']",
+			"$['store']['book'][1]['price']",
+		},
+		"$['store']['bicycle'].color": {"$['store']['bicycle']['color']"},
+		"$.store.*.color":             {"$['store']['bicycle']['color']"},
+		"$.missing":                   {},
+	}
+	for expr, want := range cases {
+		got := queryPaths(t, j, expr)
+		if len(want) == 0 && len(got) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s\nwant: %v\ngot : %v", expr, want, got)
+		}
+	}
+}
+
+func TestJSONPath_HugeSliceBounds(t *testing.T) {
+	j := mustJSONFromString(t, `[1,2,3]`)
+	cases := map[string][]string{
+		"$[1::9007199254740991]":                   {"$[1]"},
+		"$[::-9007199254740991]":                   {"$[2]"},
+		"$[-9007199254740991:9007199254740991:2]":  {"$[0]", "$[2]"},
+		"$[9007199254740991:-9007199254740991:-1]": {"$[2]", "$[1]", "$[0]"},
+		"$[-9007199254740991]":                     {},
+	}
+	for expr, want := range cases {
+		got := queryPaths(t, j, expr)
+		if len(want) == 0 && len(got) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s\nwant: %v\ngot : %v", expr, want, got)
+		}
+	}
+	for _, expr := range []string{
+		"$[1::9223372036854775807]", "$[9007199254740992]", "$[-9007199254740992:]",
+		"$[:99999999999999999999]",
+	} {
+		if _, err := j.Query(expr); err == nil {
+			t.Fatalf("expected %s to be rejected", expr)
+		}
+	}
+
+	maxStep, minStep, one := math.MaxInt, math.MinInt+1, 1
+	if got := jpSliceIndexes([3]*int{&one, nil, &maxStep}, 3); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("forward step overflow: %v", got)
+	}
+	if got := jpSliceIndexes([3]*int{nil, nil, &minStep}, 3); !reflect.DeepEqual(got, []int{2}) {
+		t.Fatalf("backward step overflow: %v", got)
+	}
+}
+
+func TestJSONPath_Descendant(t *testing.T) {
+	j := mustJSONFromString(t, jsonPathStore)
+	prices, err := j.QueryValues("$..price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prices) != 5 {
+		t.Fatalf("expected 5 prices, got %d", len(prices))
+	}
+	if got := queryPaths(t, j, "$..book[2].isbn"); len(got) != 1 || got[0] != "$['store']['book'][2]['isbn']" {
+		t.Fatalf("unexpected descendant result: %v", got)
+	}
+}
+
+func TestJSONPath_Filters(t *testing.T) {
+	j := mustJSONFromString(t, jsonPathStore)
+
+	titles := func(expr string) []string {
+		t.Helper()
+		vals, err := j.QueryValues(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		out := make([]string, len(vals))
+		for i, v := range vals {
+			out[i] = v.AsStringDefault("")
+		}
+		return out
+	}
+
+	cases := map[string][]string{
+		"$.store.book[?@.price < 10].title":                            {"Sayings of the Century", "Moby Dick"},
+		"$.store.book[?(@.isbn)].title":                                {"Moby Dick", "The Lord of the Rings"},
+		"$.store.book[?!@.isbn].title":                                 {"Sayings of the Century", "Sword of Honour"},
+		"$.store.book[?@.category == 'fiction' && @.price > 20].title": {"The Lord of the Rings"},
+		"$.store.book[?@.price == 8.95 || @.price == 22.99].title":     {"Sayings of the Century", "The Lord of the Rings"},
+		"$.store.book[?match(@.author, 'J.*')].title":                  {"The Lord of the Rings"},
+		"$.store.book[?search(@.title, 'of')].title":                   {"Sayings of the Century", "Sword of Honour", "The Lord of the Rings"},
+		"$.store.book[?length(@.title) == 9].title":                    {"Moby Dick"},
+		"$.store.book[?@.price > $.store.bicycle.price].title":         {},
+	}
+	for expr, want := range cases {
+		got := titles(expr)
+		if len(want) == 0 && len(got) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s\nwant: %v\ngot : %v", expr, want, got)
+		}
+	}
+
+	vals, err := j.QueryValues("$[?count(@.book[*]) == 4]")
+	if err != nil || len(vals) != 1 || !vals[0].IsObject() {
+		t.Fatalf("count() filter failed: %v %v", vals, err)
+	}
+}
+
+func TestJSONPath_ActiveIDs(t *testing.T) {
+	j := mustJSONFromString(t, `{"items":[
+		{"id":1,"status":"active"},
+		{"id":2,"status":"disabled"},
+		{"id":3,"status":"active"}
+	]}`)
+	ids, err := j.QueryValues("$.items[?@.status == 'active'].id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0].AsNumericDefault(0) != 1 || ids[1].AsNumericDefault(0) != 3 {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+}
+
+func TestJSONPath_NormalizedPathAndPointer(t *testing.T) {
+	j := mustJSONFromString(t, `{"a'b":{"c/d":[10,20]}}`)
+	matches, err := j.Query("$.*.*[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected one match, got %d", len(matches))
+	}
+	if matches[0].Path != `$['a\'b']['c/d'][1]` {
+		t.Fatalf("unexpected normalized path: %s", matches[0].Path)
+	}
+	if ptr := matches[0].Pointer(); ptr != "/a'b/c~1d/1" {
+		t.Fatalf("unexpected pointer: %s", ptr)
+	}
+	if j.GetByPointer(matches[0].Pointer()).AsNumericDefault(0) != 20 {
+		t.Fatalf("pointer does not resolve to the matched value")
+	}
+}
+
+func TestJSONPath_SyntaxErrors(t *testing.T) {
+	bad := []string{
+		"",
+		"store",
+		"$.",
+		"$[",
+		"$[01]",
+		"$[-0]",
+		"$[?@.a == ]",
+		"$[?@..a == 1]",
+		"$[?@.* == 1]",
+		"$[?foo(@)]",
+		"$[?count(1) == 1]",
+		"$[?1]",
+		"$['a\"]",
+		"$.a b",
+		"$[?@.a == nullx]",
+		"$[?@.a == true_]",
+		"$[?falsey == @.a]",
+		"$[?@.a == nul]",
+	}
+	for _, expr := range bad {
+		if _, err := CompileJSONPath(expr); err == nil {
+			t.Fatalf("expected syntax error for %q", expr)
+		}
+	}
+	var jpErr *JSONPathError
+	if _, err := CompileJSONPath("$[?@.a == nullx]"); !errors.As(err, &jpErr) || jpErr.Offset != 10 {
+		t.Fatalf("literal error must point at the literal: %v", err)
+	}
+	if _, err := CompileJSONPath("$[?@.a == null && @.b != true]"); err != nil {
+		t.Fatalf("literals at a word boundary: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package easyjson
+
+import "testing"
+
+func TestLookup_MissingVersusNull(t *testing.T) {
+	j := mustJSONFromString(t, `{"a":{"n":null,"v":1}}`)
+
+	v, ok := j.Lookup("a.n")
+	if !ok || !v.IsNull() || v.IsUndefined() {
+		t.Fatalf("explicit null must be found as null: ok=%v null=%v", ok, v.IsNull())
+	}
+	v, ok = j.Lookup("a.missing")
+	if ok || !v.IsUndefined() || v.IsNull() {
+		t.Fatalf("missing value must be undefined: ok=%v undefined=%v", ok, v.IsUndefined())
+	}
+	if v, ok := j.Lookup("a.v"); !ok || v.AsNumericDefault(0) != 1 {
+		t.Fatalf("expected 1, got %v (ok=%v)", v.Value, ok)
+	}
+
+	// GetByPath keeps returning null for both.
+	if !j.GetByPath("a.missing").IsNull() || !j.GetByPath("a.n").IsNull() {
+		t.Fatalf("GetByPath behaviour changed")
+	}
+}
+
+func TestAt_Chaining(t *testing.T) {
+	j := mustJSONFromString(t, `{"a":{"b":{"c":null}}}`)
+
+	if c := j.At("a").At("b").At("c"); !c.IsNull() {
+		t.Fatalf("expected null at a.b.c, undefined=%v", c.IsUndefined())
+	}
+	if c := j.At("x").At("b").At("c"); !c.IsUndefined() {
+		t.Fatalf("missing must propagate through chained lookups")
+	}
+	if _, ok := NewJSONUndefined().Lookup(""); ok {
+		t.Fatalf("lookup on undefined must report missing")
+	}
+	if NewJSONUndefined().Equals(NewJSONNull()) {
+		t.Fatalf("undefined must not equal null")
+	}
+	if got := Diff(NewJSONNull(), NewJSONUndefined()); len(got) != 1 || got[0].Type != ChangeRemoved {
+		t.Fatalf("Diff must report undefined as missing, got %q", changeSummary(got))
+	}
+	if got := Diff(NewJSONUndefined(), NewJSONUndefined()); len(got) != 0 {
+		t.Fatalf("two undefined values must not differ, got %q", changeSummary(got))
+	}
+	if NewJSONUndefined().ToString() != "null" {
+		t.Fatalf("undefined must serialize as null")
+	}
+}
+
+func TestUndefined_ClearedByMutation(t *testing.T) {
+	j := NewJSONUndefined()
+	j.DeepMerge(mustJSONFromString(t, `{"a":1}`))
+	if j.IsUndefined() || j.ToString() != `{"a":1}` {
+		t.Fatalf("DeepMerge must replace the undefined value, got %s", j.ToString())
+	}
+
+	j = NewJSONUndefined()
+	j.MergePatch(mustJSONFromString(t, `{"a":1}`))
+	if j.IsUndefined() || !j.SetByPath("b", NewJSON(2.0)) || j.ToString() != `{"a":1,"b":2}` {
+		t.Fatalf("MergePatch must replace the undefined value, got %s", j.ToString())
+	}
+
+	j = NewJSONUndefined()
+	if !j.SetByPointer("", NewJSON("x")) || j.IsUndefined() || j.Value != "x" {
+		t.Fatalf("SetByPointer must replace the undefined value, got %v", j.Value)
+	}
+
+	if b, err := NewJSONUndefined().ToCBOR(); err != nil || len(b) != 1 || b[0] != 0xf6 {
+		t.Fatalf("undefined must encode as CBOR null: %x %v", b, err)
+	}
+}
+
+func TestUndefined_NeverStored(t *testing.T) {
+	o := mustJSONFromString(t, `{"u":1,"keep":[1]}`)
+	if !o.SetByPath("u", NewJSONUndefined()) || !o.SetByPath("missing.x", NewJSONUndefined()) {
+		t.Fatalf("setting undefined must succeed by removing")
+	}
+	if _, ok := o.Lookup("u"); ok || o.PathExists("u") {
+		t.Fatalf("setting undefined must remove the key, got %s", o.ToString())
+	}
+	if !o.SetByPointer("/keep/0", NewJSONUndefined()) || o.ToString() != `{"keep":[]}` {
+		t.Fatalf("SetByPointer with undefined must remove, got %s", o.ToString())
+	}
+	o.AddToArray(NewJSONUndefined())
+	a := NewJSONArray()
+	a.AddToArray(NewJSONUndefined())
+	o.DeepMerge(NewJSONUndefined())
+	o.MergePatch(NewJSONUndefined())
+	if err := o.DeepMergeWith(NewJSONUndefined(), MergeOptions{}); err != nil || o.ToString() != `{"keep":[]}` || a.ArraySize() != 0 {
+		t.Fatalf("undefined values must not be added: %s %s %v", o.ToString(), a.ToString(), err)
+	}
+	if got := NewJSONObjectWithKeyValue("k", NewJSONUndefined()).ToString(); got != `{}` {
+		t.Fatalf("NewJSONObjectWithKeyValue: %s", got)
+	}
+}
@@ -0,0 +1,259 @@
+package easyjson
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ArrayMergeStrategy selects how two arrays are combined by DeepMergeWith.
+type ArrayMergeStrategy int
+
+const (
+	// ArrayMergeUnion appends right elements that are not already present (DeepMerge behaviour).
+	ArrayMergeUnion ArrayMergeStrategy = iota
+	// ArrayMergeReplace takes the right array as is.
+	ArrayMergeReplace
+	// ArrayMergeAppend concatenates both arrays.
+	ArrayMergeAppend
+	// ArrayMergeByIndex merges elements at the same index; extra right elements are appended.
+	ArrayMergeByIndex
+	// ArrayMergeByKey merges object elements whose KeyField values are equal; others are appended.
+	ArrayMergeByKey
+)
+
+// ScalarMergeStrategy selects how two differing scalar values are resolved.
+type ScalarMergeStrategy int
+
+const (
+	// ScalarMergeTakeRight overwrites the left value with the right one.
+	ScalarMergeTakeRight ScalarMergeStrategy = iota
+	// ScalarMergeKeepLeft keeps the left value.
+	ScalarMergeKeepLeft
+	// ScalarMergeError fails the merge.
+	ScalarMergeError
+	// ScalarMergeCustom calls MergeOptions.ScalarResolver.
+	ScalarMergeCustom
+)
+
+// TypeMismatchStrategy selects what happens when the two values have different kinds
+// (object, array or scalar).
+type TypeMismatchStrategy int
+
+const (
+	// TypeMismatchTakeRight overwrites the left value with the right one.
+	TypeMismatchTakeRight TypeMismatchStrategy = iota
+	// TypeMismatchKeepLeft keeps the left value.
+	TypeMismatchKeepLeft
+	// TypeMismatchError fails the merge.
+	TypeMismatchError
+)
+
+// ArrayMergeRule configures array merging; KeyField is used by ArrayMergeByKey.
+type ArrayMergeRule struct {
+	Strategy ArrayMergeStrategy
+	KeyField string
+}
+
+// MergeOptions configures DeepMergeWith. The zero value merges objects
+// recursively, unions arrays and lets the right side win every conflict.
+type MergeOptions struct {
+	// Arrays is the rule applied to arrays without a per-path rule.
+	Arrays ArrayMergeRule
+	// ArrayPaths overrides Arrays for the arrays at the given dot paths
+	// (array elements are addressed by index, e.g. "items.0.tags").
+	ArrayPaths map[string]ArrayMergeRule
+	Scalars    ScalarMergeStrategy
+	// ScalarResolver decides scalar conflicts when Scalars is ScalarMergeCustom.
+	ScalarResolver func(path string, left, right JSON) (JSON, error)
+	TypeMismatch   TypeMismatchStrategy
+}
+
+// MergeError reports the path at which DeepMergeWith failed.
+type MergeError struct {
+	Path string
+	Msg  string
+}
+
+func (e *MergeError) Error() string {
+	return fmt.Sprintf("merge conflict at %q: %s", e.Path, e.Msg)
+}
+
+// DeepMergeWith merges another JSON value into this one using the given options.
+// On error the JSON value is left unchanged; merging an undefined value
+// changes nothing.
+func (j *JSON) DeepMergeWith(v JSON, opts MergeOptions) error {
+	if v.IsUndefined() {
+		return nil
+	}
+	merged, err := jvDeepMergeWith("", deepCopy(j.Value), deepCopy(v.Value), &opts)
+	if err != nil {
+		return err
+	}
+	j.Value = merged
+	return nil
+}
+
+func mergeChildPath(path, tok string) string {
+	if path == "" {
+		return tok
+	}
+	return path + "." + tok
+}
+
+func jvDeepMergeWith(path string, left, right interface{}, opts *MergeOptions) (interface{}, error) {
+	if l, ok := jvObject(left); ok {
+		if r, ok := jvObject(right); ok {
+			for _, k := range jvObjectKeys(right) {
+				rv := r[k]
+				lv, exists := l[k]
+				if !exists {
+					jvObjectSet(left, k, rv)
+					continue
+				}
+				merged, err := jvDeepMergeWith(mergeChildPath(path, k), lv, rv, opts)
+				if err != nil {
+					return nil, err
+				}
+				jvObjectSet(left, k, merged)
+			}
+			return left, nil
+		}
+	} else if l, ok := left.([]interface{}); ok {
+		if r, ok := right.([]interface{}); ok {
+			rule := opts.Arrays
+			if pr, ok := opts.ArrayPaths[path]; ok {
+				rule = pr
+			}
+			return jvMergeArrays(path, l, r, rule, opts)
+		}
+	} else if !jvIsContainer(right) {
+		return jvMergeScalars(path, left, right, opts)
+	}
+
+	switch opts.TypeMismatch {
+	case TypeMismatchKeepLeft:
+		return left, nil
+	case TypeMismatchError:
+		return nil, &MergeError{Path: path, Msg: fmt.Sprintf("cannot merge %s into %s", jvKindName(right), jvKindName(left))}
+	default:
+		return right, nil
+	}
+}
+
+func jvMergeScalars(path string, left, right interface{}, opts *MergeOptions) (interface{}, error) {
+	if jvEqual(left, right) {
+		return right, nil
+	}
+	switch opts.Scalars {
+	case ScalarMergeKeepLeft:
+		return left, nil
+	case ScalarMergeError:
+		return nil, &MergeError{Path: path, Msg: fmt.Sprintf("conflicting values %s and %s", jvValueToString(left), jvValueToString(right))}
+	case ScalarMergeCustom:
+		if opts.ScalarResolver == nil {
+			return right, nil
+		}
+		res, err := opts.ScalarResolver(path, NewJSON(left), NewJSON(right))
+		if err != nil {
+			return nil, &MergeError{Path: path, Msg: err.Error()}
+		}
+		return res.Value, nil
+	default:
+		return right, nil
+	}
+}
+
+func jvMergeArrays(path string, left, right []interface{}, rule ArrayMergeRule, opts *MergeOptions) (interface{}, error) {
+	switch rule.Strategy {
+	case ArrayMergeReplace:
+		return right, nil
+
+	case ArrayMergeAppend:
+		return append(left, right...), nil
+
+	case ArrayMergeByIndex:
+		for i, rv := range right {
+			if i >= len(left) {
+				left = append(left, rv)
+				continue
+			}
+			merged, err := jvDeepMergeWith(mergeChildPath(path, strconv.Itoa(i)), left[i], rv, opts)
+			if err != nil {
+				return nil, err
+			}
+			left[i] = merged
+		}
+		return left, nil
+
+	case ArrayMergeByKey:
+		for _, rv := range right {
+			idx := -1
+			if key, ok := jvObjectField(rv, rule.KeyField); ok {
+				for i, lv := range left {
+					if lk, ok := jvObjectField(lv, rule.KeyField); ok && jvEqual(lk, key) {
+						idx = i
+						break
+					}
+				}
+			}
+			if idx < 0 {
+				left = append(left, rv)
+				continue
+			}
+			merged, err := jvDeepMergeWith(mergeChildPath(path, strconv.Itoa(idx)), left[idx], rv, opts)
+			if err != nil {
+				return nil, err
+			}
+			left[idx] = merged
+		}
+		return left, nil
+
+	default:
+		for _, rv := range right {
+			if rv == nil {
+				continue
+			}
+			dup := false
+			for _, lv := range left {
+				if lv != nil && reflect.DeepEqual(lv, rv) {
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				left = append(left, rv)
+			}
+		}
+		return left, nil
+	}
+}
+
+func jvObjectField(v interface{}, field string) (interface{}, bool) {
+	m, ok := jvObject(v)
+	if !ok {
+		return nil, false
+	}
+	f, ok := m[field]
+	return f, ok
+}
+
+func jvIsContainer(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, *OrderedObject, []interface{}:
+		return true
+	}
+	return false
+}
+
+func jvKindName(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, *OrderedObject:
+		return "object"
+	case []interface{}:
+		return "array"
+	case nil:
+		return "null"
+	}
+	return "scalar"
+}
@@ -0,0 +1,112 @@
+package easyjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func mergeWith(t *testing.T, left, right string, opts MergeOptions) string {
+	t.Helper()
+	j := mustJSONFromString(t, left)
+	if err := j.DeepMergeWith(mustJSONFromString(t, right), opts); err != nil {
+		t.Fatalf("DeepMergeWith failed: %v", err)
+	}
+	return j.ToString()
+}
+
+func TestDeepMergeWith_ArrayStrategies(t *testing.T) {
+	left := `{"a":[1,2,{"x":1}]}`
+	right := `{"a":[2,3,{"y":2}]}`
+
+	cases := map[ArrayMergeStrategy]string{
+		ArrayMergeUnion:   `{"a":[1,2,{"x":1},3,{"y":2}]}`,
+		ArrayMergeReplace: `{"a":[2,3,{"y":2}]}`,
+		ArrayMergeAppend:  `{"a":[1,2,{"x":1},2,3,{"y":2}]}`,
+		ArrayMergeByIndex: `{"a":[2,3,{"x":1,"y":2}]}`,
+	}
+	for strategy, want := range cases {
+		got := mergeWith(t, left, right, MergeOptions{Arrays: ArrayMergeRule{Strategy: strategy}})
+		if got != want {
+			t.Fatalf("strategy %d\nwant: %s\ngot : %s", strategy, want, got)
+		}
+	}
+}
+
+func TestDeepMergeWith_ByKey(t *testing.T) {
+	left := `{"items":[{"id":1,"v":"a","keep":true},{"id":2,"v":"b"}]}`
+	right := `{"items":[{"id":2,"v":"B"},{"id":3,"v":"c"},"loose"]}`
+	got := mergeWith(t, left, right, MergeOptions{Arrays: ArrayMergeRule{Strategy: ArrayMergeByKey, KeyField: "id"}})
+	want := `{"items":[{"id":1,"keep":true,"v":"a"},{"id":2,"v":"B"},{"id":3,"v":"c"},"loose"]}`
+	if got != want {
+		t.Fatalf("\nwant: %s\ngot : %s", want, got)
+	}
+}
+
+func TestDeepMergeWith_PerPathRule(t *testing.T) {
+	left := `{"tags":["a"],"nested":{"list":[1,2]}}`
+	right := `{"tags":["b"],"nested":{"list":[3]}}`
+	got := mergeWith(t, left, right, MergeOptions{
+		Arrays: ArrayMergeRule{Strategy: ArrayMergeAppend},
+		ArrayPaths: map[string]ArrayMergeRule{
+			"nested.list": {Strategy: ArrayMergeReplace},
+		},
+	})
+	want := `{"nested":{"list":[3]},"tags":["a","b"]}`
+	if got != want {
+		t.Fatalf("\nwant: %s\ngot : %s", want, got)
+	}
+}
+
+func TestDeepMergeWith_Scalars(t *testing.T) {
+	left := `{"a":1,"b":"x","same":true}`
+	right := `{"a":2,"b":"y","same":true}`
+
+	if got := mergeWith(t, left, right, MergeOptions{Scalars: ScalarMergeKeepLeft}); got != `{"a":1,"b":"x","same":true}` {
+		t.Fatalf("keep left: %s", got)
+	}
+	if got := mergeWith(t, left, right, MergeOptions{}); got != `{"a":2,"b":"y","same":true}` {
+		t.Fatalf("take right: %s", got)
+	}
+
+	got := mergeWith(t, left, right, MergeOptions{
+		Scalars: ScalarMergeCustom,
+		ScalarResolver: func(path string, l, r JSON) (JSON, error) {
+			if path == "a" {
+				return NewJSON(l.AsNumericDefault(0) + r.AsNumericDefault(0)), nil
+			}
+			return l, nil
+		},
+	})
+	if got != `{"a":3,"b":"x","same":true}` {
+		t.Fatalf("custom: %s", got)
+	}
+
+	j := mustJSONFromString(t, left)
+	err := j.DeepMergeWith(mustJSONFromString(t, right), MergeOptions{Scalars: ScalarMergeError})
+	var merr *MergeError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected *MergeError, got %v", err)
+	}
+	if j.ToString() != `{"a":1,"b":"x","same":true}` {
+		t.Fatalf("failed merge must leave the value untouched, got %s", j.ToString())
+	}
+}
+
+func TestDeepMergeWith_TypeMismatch(t *testing.T) {
+	left := `{"a":{"x":1},"b":[1]}`
+	right := `{"a":"str","b":{"y":2}}`
+
+	if got := mergeWith(t, left, right, MergeOptions{}); got != `{"a":"str","b":{"y":2}}` {
+		t.Fatalf("take right: %s", got)
+	}
+	if got := mergeWith(t, left, right, MergeOptions{TypeMismatch: TypeMismatchKeepLeft}); got != left {
+		t.Fatalf("keep left: %s", got)
+	}
+
+	j := mustJSONFromString(t, left)
+	err := j.DeepMergeWith(mustJSONFromString(t, right), MergeOptions{TypeMismatch: TypeMismatchError})
+	var merr *MergeError
+	if !errors.As(err, &merr) || (merr.Path != "a" && merr.Path != "b") {
+		t.Fatalf("expected *MergeError at a or b, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package easyjson
+
+// RFC 7396 JSON Merge Patch support.
+//
+// Unlike DeepMerge, a merge patch removes object members whose patch value
+// is null and replaces arrays wholesale instead of unioning them.
+
+// MergePatch applies an RFC 7396 merge patch to the JSON value.
+// An undefined patch changes nothing.
+func (j *JSON) MergePatch(patch JSON) {
+	if patch.IsUndefined() {
+		return
+	}
+	j.Value = jvMergePatch(j.Value, patch.Value)
+}
+
+func jvMergePatch(target, patch interface{}) interface{} {
+	p, ok := jvObject(patch)
+	if !ok {
+		return deepCopy(patch)
+	}
+	if _, ok := jvObject(target); !ok {
+		target = jvNewObjectLike(patch)
+	}
+	t, _ := jvObject(target)
+	for _, k := range jvObjectKeys(patch) {
+		v := p[k]
+		if v == nil {
+			jvObjectDelete(target, k)
+			continue
+		}
+		jvObjectSet(target, k, jvMergePatch(t[k], v))
+	}
+	return target
+}
+
+// CreateMergePatch computes the merge patch that transforms from into to.
+// Since null marks a deletion in a merge patch, object members whose value
+// in to is null cannot be expressed and are treated as removed.
+func CreateMergePatch(from, to JSON) JSON {
+	return NewJSON(jvCreateMergePatch(from.Value, to.Value))
+}
+
+func jvCreateMergePatch(from, to interface{}) interface{} {
+	f, fok := jvObject(from)
+	t, tok := jvObject(to)
+	if !fok || !tok {
+		return deepCopy(to)
+	}
+	patch := jvNewObjectLike(to)
+	for _, k := range jvObjectKeys(from) {
+		if v, ok := t[k]; !ok || v == nil {
+			jvObjectSet(patch, k, nil)
+		}
+	}
+	for _, k := range jvObjectKeys(to) {
+		tv := t[k]
+		if tv == nil {
+			continue
+		}
+		fv, ok := f[k]
+		if !ok {
+			jvObjectSet(patch, k, deepCopy(tv))
+			continue
+		}
+		if jvEqual(fv, tv) {
+			continue
+		}
+		jvObjectSet(patch, k, jvCreateMergePatch(fv, tv))
+	}
+	return patch
+}
@@ -0,0 +1,63 @@
+package easyjson
+
+import "testing"
+
+// Test cases from RFC 7396, appendix A.
+func TestMergePatch_RFCExamples(t *testing.T) {
+	cases := []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for i, c := range cases {
+		j := mustJSONFromString(t, c.target)
+		j.MergePatch(mustJSONFromString(t, c.patch))
+		if got := j.ToString(); got != c.want {
+			t.Fatalf("case %d\nwant: %s\ngot : %s", i, c.want, got)
+		}
+	}
+}
+
+func TestMergePatch_DoesNotAliasPatch(t *testing.T) {
+	j := NewJSONObject()
+	patch := mustJSONFromString(t, `{"a":{"b":[1,2]}}`)
+	j.MergePatch(patch)
+	j.SetByPath("a.c", NewJSON(3))
+	if patch.PathExists("a.c") {
+		t.Fatalf("modifying the result must not modify the patch")
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	cases := []struct{ from, to, want string }{
+		{`{"a":"b","c":{"d":"e","f":"g"}}`, `{"a":"z","c":{"d":"e"}}`, `{"a":"z","c":{"f":null}}`},
+		{`{"a":[1,2]}`, `{"a":[1,2,3]}`, `{"a":[1,2,3]}`},
+		{`{"a":1}`, `{"a":1}`, `{}`},
+		{`{"a":1}`, `[1]`, `[1]`},
+		{`{"a":{"b":1}}`, `{"a":"x","n":{"m":true}}`, `{"a":"x","n":{"m":true}}`},
+	}
+	for i, c := range cases {
+		from := mustJSONFromString(t, c.from)
+		to := mustJSONFromString(t, c.to)
+		patch := CreateMergePatch(from, to)
+		if got := patch.ToString(); got != c.want {
+			t.Fatalf("case %d\nwant: %s\ngot : %s", i, c.want, got)
+		}
+		from.MergePatch(patch)
+		if !from.Equals(to) {
+			t.Fatalf("case %d: applying generated patch gave %s", i, from.ToString())
+		}
+	}
+}
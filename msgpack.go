@@ -0,0 +1,537 @@
+package easyjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+	"unicode/utf8"
+)
+
+// MessagePack support.
+//
+// Integers and floats keep their distinction in both directions: Go integer
+// types, *big.Int and integral json.Number values are encoded as msgpack
+// integers, float64 and float32 values as msgpack floats of the same width,
+// and decoding yields int64 (uint64 above math.MaxInt64), float64 and
+// float32. Numbers parsed from JSON text are float64 unless a NumberMode
+// says otherwise, so use NumberTyped when integers should stay integers.
+//
+// Binary data follows the NewJSONBytes convention as for CBOR: bin values
+// decode to strings in the default byte encoding that AsBytes turns back
+// into bytes, and []byte values (or such strings, with
+// MsgPackEncodeOptions.StringsAsBytes) encode as bin.
+
+// MsgPackEncodeOptions configures ToMsgPack.
+type MsgPackEncodeOptions struct {
+	// StringsAsBytes encodes strings that hold bytes exactly as NewJSONBytes
+	// writes them (lowercase hex by default) as bin values.
+	StringsAsBytes bool
+}
+
+// MsgPackDecodeOptions configures JSONFromMsgPack and MsgPackDecoder.
+type MsgPackDecodeOptions struct {
+	// PreserveKeyOrder decodes maps into *OrderedObject values that keep the
+	// key order of the input.
+	PreserveKeyOrder bool
+}
+
+// msgpackTimestampExt is the extension type of msgpack timestamps.
+const msgpackTimestampExt = -1
+
+// ToMsgPack encodes the JSON value as MessagePack. Integers that do not fit
+// into 64 bits cannot be represented and are reported as errors.
+func (j JSON) ToMsgPack(opts ...MsgPackEncodeOptions) ([]byte, error) {
+	e := &msgpackEncoder{}
+	if len(opts) > 0 {
+		e.opts = opts[0]
+	}
+	if err := e.value(j.Value); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+type msgpackEncoder struct {
+	opts MsgPackEncodeOptions
+	buf  bytes.Buffer
+}
+
+// head writes a type byte followed by n as a big-endian integer of size bytes.
+func (e *msgpackEncoder) head(code byte, n uint64, size int) {
+	var b [9]byte
+	b[0] = code
+	for i := 0; i < size; i++ {
+		b[size-i] = byte(n >> (8 * i))
+	}
+	e.buf.Write(b[:1+size])
+}
+
+// length writes a str, bin, array or map header: the fix form when fixBase
+// is non-zero and n allows it, the 8, 16 or 32 bit form otherwise. code8 is
+// 0 for arrays and maps, which have no 8 bit form.
+func (e *msgpackEncoder) length(fixBase byte, fixMax int, code8, code16, code32 byte, n int) error {
+	switch {
+	case fixBase != 0 && n <= fixMax:
+		e.buf.WriteByte(fixBase | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		e.head(code8, uint64(n), 1)
+	case n <= math.MaxUint16:
+		e.head(code16, uint64(n), 2)
+	case uint64(n) <= math.MaxUint32:
+		e.head(code32, uint64(n), 4)
+	default:
+		return fmt.Errorf("easyjson: length %d exceeds the MessagePack limit", n)
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) value(v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		e.buf.WriteByte(0xc0)
+	case bool:
+		if x {
+			e.buf.WriteByte(0xc3)
+		} else {
+			e.buf.WriteByte(0xc2)
+		}
+	case string:
+		if e.opts.StringsAsBytes {
+			if b, ok := jvBytesOf(x); ok {
+				return e.bin(b)
+			}
+		}
+		return e.str(x)
+	case []byte:
+		return e.bin(x)
+	case float64:
+		e.head(0xcb, math.Float64bits(x), 8)
+	case float32:
+		e.head(0xca, uint64(math.Float32bits(x)), 4)
+	case json.Number:
+		if b, ok := new(big.Int).SetString(string(x), 10); ok {
+			return e.bigInt(b)
+		}
+		f, err := x.Float64()
+		if err != nil {
+			return err
+		}
+		e.head(0xcb, math.Float64bits(f), 8)
+	case *big.Int:
+		if x == nil {
+			e.buf.WriteByte(0xc0)
+			break
+		}
+		return e.bigInt(x)
+	case []interface{}:
+		if err := e.length(0x90, 15, 0, 0xdc, 0xdd, len(x)); err != nil {
+			return err
+		}
+		for _, el := range x {
+			if err := e.value(el); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}, *OrderedObject:
+		obj, _ := jvObject(x)
+		keys := jvObjectKeys(x)
+		if err := e.length(0x80, 15, 0, 0xde, 0xdf, len(keys)); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			// Keys are always str, even under StringsAsBytes.
+			if err := e.str(k); err != nil {
+				return err
+			}
+			if err := e.value(obj[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		if n, _, ok := jvToInt64(v); ok {
+			e.int(n)
+			break
+		}
+		if n, _, ok := jvToUint64(v); ok {
+			e.uint(n)
+			break
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var generic interface{}
+		if err := dec.Decode(&generic); err != nil {
+			return err
+		}
+		return e.value(generic)
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) str(s string) error {
+	if err := e.length(0xa0, 31, 0xd9, 0xda, 0xdb, len(s)); err != nil {
+		return err
+	}
+	e.buf.WriteString(s)
+	return nil
+}
+
+func (e *msgpackEncoder) bin(b []byte) error {
+	if err := e.length(0, 0, 0xc4, 0xc5, 0xc6, len(b)); err != nil {
+		return err
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+func (e *msgpackEncoder) uint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint8:
+		e.head(0xcc, n, 1)
+	case n <= math.MaxUint16:
+		e.head(0xcd, n, 2)
+	case n <= math.MaxUint32:
+		e.head(0xce, n, 4)
+	default:
+		e.head(0xcf, n, 8)
+	}
+}
+
+func (e *msgpackEncoder) int(n int64) {
+	switch {
+	case n >= 0:
+		e.uint(uint64(n))
+	case n >= -32:
+		e.buf.WriteByte(byte(n))
+	case n >= math.MinInt8:
+		e.head(0xd0, uint64(n), 1)
+	case n >= math.MinInt16:
+		e.head(0xd1, uint64(n), 2)
+	case n >= math.MinInt32:
+		e.head(0xd2, uint64(n), 4)
+	default:
+		e.head(0xd3, uint64(n), 8)
+	}
+}
+
+func (e *msgpackEncoder) bigInt(b *big.Int) error {
+	switch {
+	case b.IsInt64():
+		e.int(b.Int64())
+	case b.IsUint64():
+		e.uint(b.Uint64())
+	default:
+		return fmt.Errorf("easyjson: integer %s does not fit into MessagePack", b)
+	}
+	return nil
+}
+
+// JSONFromMsgPack decodes a single MessagePack value into a JSON value tree.
+// Map keys that are not strings are stringified (42, true, null, the encoded
+// bytes of a bin key or the JSON text of a composite key) and timestamps become
+// RFC 3339 strings. Other extension types are rejected.
+func JSONFromMsgPack(b []byte, opts ...MsgPackDecodeOptions) (JSON, error) {
+	d := NewMsgPackDecoder(bytes.NewReader(b), opts...)
+	j, err := d.Decode()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = d.errorf("unexpected end of input")
+		}
+		return NewJSONNull(), err
+	}
+	if d.More() {
+		return NewJSONNull(), d.errorf("unexpected data after MessagePack value")
+	}
+	return j, nil
+}
+
+// MsgPackDecoder reads a stream of MessagePack values from an io.Reader,
+// such as the messages of a connection, one JSON value at a time.
+//
+//	d := easyjson.NewMsgPackDecoder(conn)
+//	for {
+//		msg, err := d.Decode()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+type MsgPackDecoder struct {
+	r      *bufio.Reader
+	opts   MsgPackDecodeOptions
+	offset int64
+	depth  int
+}
+
+// NewMsgPackDecoder creates a decoder of the MessagePack values read from r.
+func NewMsgPackDecoder(r io.Reader, opts ...MsgPackDecodeOptions) *MsgPackDecoder {
+	d := &MsgPackDecoder{r: bufio.NewReader(r)}
+	if len(opts) > 0 {
+		d.opts = opts[0]
+	}
+	return d
+}
+
+// More reports whether there is another value to decode.
+func (d *MsgPackDecoder) More() bool {
+	_, err := d.r.Peek(1)
+	return err == nil
+}
+
+// Decode reads the next value. It returns io.EOF when the stream ends
+// between values, and an error wrapping io.ErrUnexpectedEOF when it ends
+// inside one.
+func (d *MsgPackDecoder) Decode() (JSON, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return NewJSONNull(), err
+	}
+	d.depth = 0
+	v, err := d.value()
+	if err != nil {
+		return NewJSONNull(), err
+	}
+	return NewJSON(v), nil
+}
+
+func (d *MsgPackDecoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("easyjson: invalid MessagePack at offset %d: %s", d.offset, fmt.Sprintf(format, args...))
+}
+
+// read returns the next n bytes. Large lengths are read incrementally so that
+// a corrupt length cannot force a huge allocation up front.
+func (d *MsgPackDecoder) read(n uint64) ([]byte, error) {
+	const chunk = 64 << 10
+	var buf bytes.Buffer
+	if n <= chunk {
+		buf.Grow(int(n))
+	}
+	m, err := io.CopyN(&buf, d.r, int64(n))
+	d.offset += m
+	if err != nil {
+		if err == io.EOF {
+			err = fmt.Errorf("easyjson: invalid MessagePack at offset %d: %w", d.offset, io.ErrUnexpectedEOF)
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *MsgPackDecoder) uint(size int) (uint64, error) {
+	b, err := d.read(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+func (d *MsgPackDecoder) value() (interface{}, error) {
+	start := d.offset
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.object(uint64(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.array(uint64(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.str(uint64(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return encodeBytes(data, DefaultByteEncoding()), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n, start)
+	case 0xca:
+		n, err := d.uint(4)
+		return math.Float32frombits(uint32(n)), err
+	case 0xcb:
+		n, err := d.uint(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*size // sign-extend
+		return int64(n<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1<<(c-0xd4), start)
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.object(n)
+	}
+	d.offset = start
+	return nil, d.errorf("invalid type byte 0x%02x", c)
+}
+
+func (d *MsgPackDecoder) str(n uint64) (interface{}, error) {
+	start := d.offset
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(b) {
+		d.offset = start
+		return nil, d.errorf("invalid UTF-8 in string")
+	}
+	return string(b), nil
+}
+
+func (d *MsgPackDecoder) enter() error {
+	d.depth++
+	if d.depth > defaultMaxDepth {
+		return d.errorf("nesting depth exceeds %d", defaultMaxDepth)
+	}
+	return nil
+}
+
+func (d *MsgPackDecoder) array(n uint64) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	const maxPrealloc = 1024
+	arr := make([]interface{}, 0, minUint64(n, maxPrealloc))
+	for i := uint64(0); i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func (d *MsgPackDecoder) object(n uint64) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	var obj interface{} = map[string]interface{}{}
+	if d.opts.PreserveKeyOrder {
+		obj = NewOrderedObject()
+	}
+	for i := uint64(0); i < n; i++ {
+		start := d.offset
+		kv, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		key, err := jvKeyString(kv)
+		if err != nil {
+			d.offset = start
+			return nil, d.errorf("unsupported map key: %v", err)
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		jvObjectSet(obj, key, v)
+	}
+	return obj, nil
+}
+
+// ext decodes an extension value with n bytes of data; only timestamps are
+// supported.
+func (d *MsgPackDecoder) ext(n uint64, start int64) (interface{}, error) {
+	t, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(t[0]) != msgpackTimestampExt {
+		d.offset = start
+		return nil, d.errorf("unsupported extension type %d", int8(t[0]))
+	}
+	var ts time.Time
+	switch n {
+	case 4:
+		ts = time.Unix(int64(binary.BigEndian.Uint32(data)), 0)
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		ts = time.Unix(int64(v&(1<<34-1)), int64(v>>34))
+	case 12:
+		ts = time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data)))
+	default:
+		d.offset = start
+		return nil, d.errorf("invalid timestamp length %d", n)
+	}
+	return ts.UTC().Format(time.RFC3339Nano), nil
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
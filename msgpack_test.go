@@ -0,0 +1,151 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestToMsgPack(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "c0"}, {false, "c2"}, {true, "c3"},
+		{0, "00"}, {127, "7f"}, {128, "cc80"}, {256, "cd0100"}, {int64(1) << 32, "cf0000000100000000"},
+		{uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{-1, "ff"}, {-32, "e0"}, {-33, "d0df"}, {-129, "d1ff7f"}, {int64(math.MinInt64), "d38000000000000000"},
+		{1.5, "cb3ff8000000000000"}, {float32(1.5), "ca3fc00000"},
+		{"", "a0"}, {strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32)},
+		{[]byte{1, 2}, "c4020102"},
+		{[]interface{}{1, "a"}, "9201a161"},
+		{map[string]interface{}{"b": 2, "a": 1}, "82a16101a16202"},
+	}
+	for _, c := range cases {
+		got, err := JSON{Value: c.v}.ToMsgPack()
+		if err != nil {
+			t.Errorf("%v: %v", c.v, err)
+			continue
+		}
+		if hex.EncodeToString(got) != c.want {
+			t.Errorf("%v: got %x, want %s", c.v, got, c.want)
+		}
+	}
+
+	j, _ := JSONFromStringWithOptions(`[1,1.0,18446744073709551615]`, ParseOptions{Numbers: NumberTyped})
+	got, _ := j.ToMsgPack()
+	if hex.EncodeToString(got) != "9301cb3ff0000000000000cfffffffffffffffff" {
+		t.Errorf("typed numbers: %x", got)
+	}
+	j, _ = JSONFromStringWithOptions(`18446744073709551616`, ParseOptions{Numbers: NumberTyped})
+	if _, err := j.ToMsgPack(); err == nil {
+		t.Error("expected an error for an integer beyond 64 bits")
+	}
+
+	hexStr, _ := NewJSONBytes([]byte{0xca, 0xfe}).ToMsgPack(MsgPackEncodeOptions{StringsAsBytes: true})
+	if hex.EncodeToString(hexStr) != "c402cafe" {
+		t.Errorf("hex as bytes: %x", hexStr)
+	}
+
+	keyed, _ := JSONFromString(`{"abcd":"abcd","cafe":{"00":1}}`)
+	packed, err := keyed.ToMsgPack(MsgPackEncodeOptions{StringsAsBytes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "82a461626364c402abcda463616665" + "81a23030cb3ff0000000000000"; hex.EncodeToString(packed) != want {
+		t.Errorf("keys must stay str: got %x, want %s", packed, want)
+	}
+	if back, err := JSONFromMsgPack(packed); err != nil || !back.Equals(keyed) {
+		t.Errorf("round trip: %s %v", back.ToString(), err)
+	}
+}
+
+func TestJSONFromMsgPack(t *testing.T) {
+	j, _ := JSONFromStringWithOptions(`{"id":42,"neg":-7,"ratio":0.5,"ok":true,"tags":["a","b"],"none":null}`, ParseOptions{Numbers: NumberTyped})
+	b, err := j.ToMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := JSONFromMsgPack(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !back.Equals(j) {
+		t.Fatalf("round trip: %s", back.ToString())
+	}
+	if _, ok := back.GetByPath("id").Value.(int64); !ok {
+		t.Errorf("integers must stay integers: %#v", back.GetByPath("id").Value)
+	}
+	if _, ok := back.GetByPath("ratio").Value.(float64); !ok {
+		t.Errorf("floats must stay floats: %#v", back.GetByPath("ratio").Value)
+	}
+
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"c4020102", `"0102"`},
+		{"ca3fc00000", `1.5`},
+		{"d0df", `-33`},
+		{"cfffffffffffffffff", `18446744073709551615`},
+		{"83c0a16101a162c3a163", `{"1":"b","null":"a","true":"c"}`},
+		{"d6ff00000000", `"1970-01-01T00:00:00Z"`},
+		{"c70cff00000001000000000000000a", `"1970-01-01T00:00:10.000000001Z"`},
+		{"dc000190", `[[]]`},
+	}
+	for _, c := range cases {
+		in, _ := hex.DecodeString(c.in)
+		j, err := JSONFromMsgPack(in)
+		if err != nil {
+			t.Errorf("%s: %v", c.in, err)
+			continue
+		}
+		if got := j.ToString(); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.in, got, c.want)
+		}
+	}
+
+	ordered, _ := hex.DecodeString("82a17a01a16102")
+	if j, err := JSONFromMsgPack(ordered, MsgPackDecodeOptions{PreserveKeyOrder: true}); err != nil || j.ToString() != `{"z":1,"a":2}` {
+		t.Errorf("key order: %s %v", j.ToString(), err)
+	}
+
+	for _, bad := range []string{"", "c1", "cd01", "a2ff", "a2c3c3", "92c0", "0101", "d40100", "c6ffffffff"} {
+		in, _ := hex.DecodeString(bad)
+		if _, err := JSONFromMsgPack(in); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+	in, _ := hex.DecodeString("92c0")
+	if _, err := JSONFromMsgPack(in); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated input must wrap io.ErrUnexpectedEOF: %v", err)
+	}
+}
+
+func TestMsgPackDecoder(t *testing.T) {
+	var stream bytes.Buffer
+	for _, s := range []string{`{"n":1}`, `"two"`, `[3]`} {
+		j, _ := JSONFromString(s)
+		b, _ := j.ToMsgPack()
+		stream.Write(b)
+	}
+	d := NewMsgPackDecoder(&stream)
+	var got []string
+	for {
+		j, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, j.ToString())
+	}
+	if strings.Join(got, " ") != `{"n":1} "two" [3]` {
+		t.Fatalf("got %v", got)
+	}
+}
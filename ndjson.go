@@ -0,0 +1,183 @@
+package easyjson
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NDJSON (newline-delimited JSON, also known as JSON Lines) support.
+
+// NDJSONLineError reports a line that could not be parsed.
+// Err is the *ParseError describing the problem within the line.
+type NDJSONLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *NDJSONLineError) Error() string {
+	return fmt.Sprintf("easyjson: ndjson line %d: %v", e.Line, e.Err)
+}
+
+func (e *NDJSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSONReaderOptions configures an NDJSONReader.
+type NDJSONReaderOptions struct {
+	// SkipInvalid skips lines that are not valid JSON instead of stopping.
+	SkipInvalid bool
+	// OnInvalid, if set, is called for every skipped line in SkipInvalid mode.
+	OnInvalid func(err *NDJSONLineError)
+}
+
+// NDJSONReader reads one JSON document per line. Blank lines are ignored.
+//
+//	r := easyjson.NewNDJSONReader(f)
+//	for r.Next() {
+//		doc := r.Value()
+//		...
+//	}
+//	if err := r.Err(); err != nil { ... }
+type NDJSONReader struct {
+	r       *bufio.Reader
+	opts    NDJSONReaderOptions
+	line    int
+	cur     JSON
+	err     error
+	skipped int
+}
+
+// NewNDJSONReader creates a reader of newline-delimited JSON documents.
+func NewNDJSONReader(r io.Reader, opts ...NDJSONReaderOptions) *NDJSONReader {
+	nr := &NDJSONReader{r: bufio.NewReader(r)}
+	if len(opts) > 0 {
+		nr.opts = opts[0]
+	}
+	return nr
+}
+
+// Next advances to the next document. It returns false at the end of the
+// input or on the first error, which is then available from Err.
+func (r *NDJSONReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	for {
+		raw, err := r.r.ReadBytes('\n')
+		if len(raw) == 0 && err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+			return false
+		}
+		r.line++
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 {
+			j, perr := ParseJSON(raw)
+			if perr == nil {
+				r.cur = j
+				return true
+			}
+			lerr := &NDJSONLineError{Line: r.line, Err: perr}
+			if !r.opts.SkipInvalid {
+				r.err = lerr
+				return false
+			}
+			r.skipped++
+			if r.opts.OnInvalid != nil {
+				r.opts.OnInvalid(lerr)
+			}
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+			return false
+		}
+	}
+}
+
+// Value returns the document read by the last successful call to Next.
+func (r *NDJSONReader) Value() JSON {
+	return r.cur
+}
+
+// Line returns the 1-based line number of the document returned by Value.
+func (r *NDJSONReader) Line() int {
+	return r.line
+}
+
+// Skipped returns the number of invalid lines skipped so far.
+func (r *NDJSONReader) Skipped() int {
+	return r.skipped
+}
+
+// Err returns the first error encountered, or nil at a clean end of input.
+func (r *NDJSONReader) Err() error {
+	return r.err
+}
+
+// ReadAll reads all remaining documents.
+func (r *NDJSONReader) ReadAll() ([]JSON, error) {
+	var docs []JSON
+	for r.Next() {
+		docs = append(docs, r.Value())
+	}
+	return docs, r.Err()
+}
+
+// NDJSONWriterOptions configures an NDJSONWriter.
+type NDJSONWriterOptions struct {
+	// Buffered collects output in memory; call Flush to write it out.
+	Buffered bool
+	// BufferSize is the buffer size in Buffered mode; 0 means the bufio default.
+	BufferSize int
+}
+
+// NDJSONWriter writes one JSON document per line.
+type NDJSONWriter struct {
+	w  io.Writer
+	bw *bufio.Writer
+}
+
+// NewNDJSONWriter creates a writer of newline-delimited JSON documents.
+func NewNDJSONWriter(w io.Writer, opts ...NDJSONWriterOptions) *NDJSONWriter {
+	nw := &NDJSONWriter{w: w}
+	if len(opts) > 0 && opts[0].Buffered {
+		if opts[0].BufferSize > 0 {
+			nw.bw = bufio.NewWriterSize(w, opts[0].BufferSize)
+		} else {
+			nw.bw = bufio.NewWriter(w)
+		}
+	}
+	return nw
+}
+
+// Write serializes j with ToBytes and writes it followed by a newline.
+func (w *NDJSONWriter) Write(j JSON) error {
+	b := j.ToBytes()
+	if len(b) == 0 {
+		return errors.New("easyjson: ndjson value cannot be serialized")
+	}
+	line := make([]byte, 0, len(b)+1)
+	line = append(append(line, b...), '\n')
+	if w.bw != nil {
+		_, err := w.bw.Write(line)
+		return err
+	}
+	_, err := w.w.Write(line)
+	return err
+}
+
+// Flush writes any buffered output to the underlying writer.
+func (w *NDJSONWriter) Flush() error {
+	if w.bw == nil {
+		return nil
+	}
+	return w.bw.Flush()
+}
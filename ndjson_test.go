@@ -0,0 +1,86 @@
+package easyjson
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONReader(t *testing.T) {
+	src := "{\"id\":1}\n\n  [1,2]\r\n\"str\"\n{\"id\":2}"
+	r := NewNDJSONReader(strings.NewReader(src))
+	docs, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(docs) != 4 {
+		t.Fatalf("expected 4 documents, got %d", len(docs))
+	}
+	if docs[1].ArraySize() != 2 || docs[2].AsStringDefault("") != "str" || docs[3].GetByPath("id").AsNumericDefault(0) != 2 {
+		t.Fatalf("unexpected documents: %v", docs)
+	}
+	if r.Line() != 5 {
+		t.Fatalf("expected to end on line 5, got %d", r.Line())
+	}
+}
+
+func TestNDJSONReader_InvalidLine(t *testing.T) {
+	src := "{\"a\":1}\n{broken\n{\"a\":3}\n"
+
+	r := NewNDJSONReader(strings.NewReader(src))
+	docs, err := r.ReadAll()
+	var lerr *NDJSONLineError
+	if !errors.As(err, &lerr) || lerr.Line != 2 {
+		t.Fatalf("expected error on line 2, got %v", err)
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Column != 2 {
+		t.Fatalf("expected parse error details, got %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("expected one document before the error, got %d", len(docs))
+	}
+
+	var reported []int
+	r = NewNDJSONReader(strings.NewReader(src), NDJSONReaderOptions{
+		SkipInvalid: true,
+		OnInvalid:   func(e *NDJSONLineError) { reported = append(reported, e.Line) },
+	})
+	docs, err = r.ReadAll()
+	if err != nil || len(docs) != 2 || r.Skipped() != 1 {
+		t.Fatalf("skip mode: docs=%d skipped=%d err=%v", len(docs), r.Skipped(), err)
+	}
+	if len(reported) != 1 || reported[0] != 2 {
+		t.Fatalf("OnInvalid not called for line 2: %v", reported)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewNDJSONWriter(&buf)
+	w.Write(mustJSONFromString(t, `{"a":"line\nbreak"}`))
+	w.Write(NewJSON([]int{1, 2}))
+	if got := buf.String(); got != "{\"a\":\"line\\nbreak\"}\n[1,2]\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+	if err := w.Write(NewJSON(math.NaN())); err == nil {
+		t.Fatalf("unserializable values must fail")
+	}
+
+	buf.Reset()
+	bw := NewNDJSONWriter(&buf, NDJSONWriterOptions{Buffered: true})
+	bw.Write(NewJSON(1))
+	if buf.Len() != 0 {
+		t.Fatalf("buffered writer must not write before Flush")
+	}
+	if err := bw.Flush(); err != nil || buf.String() != "1\n" {
+		t.Fatalf("unexpected flushed output %q (%v)", buf.String(), err)
+	}
+
+	docs, err := NewNDJSONReader(&buf).ReadAll()
+	if err != nil || len(docs) != 1 {
+		t.Fatalf("roundtrip failed: %v %v", docs, err)
+	}
+}
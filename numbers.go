@@ -0,0 +1,207 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// NumberMode selects how JSONFromBytesWithOptions represents numbers.
+type NumberMode int
+
+const (
+	// NumberFloat64 decodes every number to float64, like JSONFromBytes.
+	// Integers above 2^53 lose precision.
+	NumberFloat64 NumberMode = iota
+	// NumberJSONNumber keeps every number as a json.Number holding the
+	// original text, so ToBytes reproduces the input digits exactly.
+	NumberJSONNumber
+	// NumberTyped decodes integers to int64, uint64 or *big.Int, whichever
+	// is the smallest that holds the value exactly, and other numbers to
+	// float64. Numbers that overflow or underflow float64 are kept as
+	// json.Number.
+	NumberTyped
+)
+
+func parseNumber(n json.Number, mode NumberMode) (interface{}, error) {
+	switch mode {
+	case NumberJSONNumber:
+		return n, nil
+	case NumberTyped:
+		s := string(n)
+		if !strings.ContainsAny(s, ".eE") {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+			if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+				return u, nil
+			}
+			if b, ok := new(big.Int).SetString(s, 10); ok {
+				return b, nil
+			}
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || (f == 0 && hasNonZeroMantissa(s)) {
+			return n, nil
+		}
+		return f, nil
+	}
+	return n.Float64()
+}
+
+// floatForMode represents a float decoded from a non-JSON format according
+// to mode: as the json.Number of its shortest text under NumberJSONNumber,
+// and as float64 otherwise or if it is not finite.
+func floatForMode(f float64, mode NumberMode) interface{} {
+	if mode == NumberJSONNumber && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return json.Number(appendShortestFloat(nil, f, 64))
+	}
+	return f
+}
+
+// hasNonZeroMantissa reports whether a number literal has a non-zero digit
+// before its exponent, i.e. whether a float64 of 0 lost it to underflow.
+func hasNonZeroMantissa(s string) bool {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.ContainsAny(s, "123456789")
+}
+
+// AsInt64 returns the JSON value as an int64 if it is an integral number
+// that fits, without going through float64.
+func (j JSON) AsInt64() (int64, bool) {
+	n, _, ok := jvToInt64(j.Value)
+	return n, ok
+}
+
+// AsUint64 returns the JSON value as a uint64 if it is a non-negative
+// integral number that fits, without going through float64.
+func (j JSON) AsUint64() (uint64, bool) {
+	n, _, ok := jvToUint64(j.Value)
+	return n, ok
+}
+
+// AsBigInt returns the JSON value as a *big.Int if it is an integral number.
+// Integers written out in digits may have any length. For a json.Number in
+// exponent notation the exponent may exceed the number of significant digits
+// by at most 400, so that untrusted input cannot force a huge allocation:
+// 1e30 and 1e400 are accepted, 1e500 is not.
+func (j JSON) AsBigInt() (*big.Int, bool) {
+	return jvToBigInt(j.Value)
+}
+
+// AsDecimalString returns the exact decimal text of a number: the original
+// digits for json.Number, and plain notation without an exponent otherwise.
+func (j JSON) AsDecimalString() (string, bool) {
+	switch x := j.Value.(type) {
+	case json.Number:
+		return x.String(), true
+	case *big.Int:
+		if x == nil {
+			return "", false
+		}
+		return x.String(), true
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32), true
+	case int, int8, int16, int32, int64:
+		n, _, _ := jvToInt64(x)
+		return strconv.FormatInt(n, 10), true
+	case uint, uint8, uint16, uint32, uint64:
+		n, _, _ := jvToUint64(x)
+		return strconv.FormatUint(n, 10), true
+	}
+	return "", false
+}
+
+// maxExponentSlack bounds how far the exponent of a decimal number may
+// exceed its digit count for decimalToBigInt, so that text such as 1e1000000
+// cannot make it build an arbitrarily large integer.
+const maxExponentSlack = 400
+
+// decimalToBigInt converts the decimal number text s to *big.Int if it is
+// integral. It parses the mantissa and exponent itself and rejects an
+// exponent beyond maxExponentSlack before doing any big number arithmetic.
+func decimalToBigInt(s string) (*big.Int, bool) {
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	mant, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil {
+			return nil, false
+		}
+		mant, exp = s[:i], e
+	}
+	intPart, frac := mant, ""
+	if i := strings.IndexByte(mant, '.'); i >= 0 {
+		intPart, frac = mant[:i], mant[i+1:]
+	}
+	if intPart == "" || !isDecimalDigits(intPart) || !isDecimalDigits(frac) {
+		return nil, false
+	}
+	digits := strings.TrimLeft(intPart+frac, "0")
+	trimmed := strings.TrimRight(digits, "0")
+	if trimmed == "" {
+		return new(big.Int), true
+	}
+	// exp is now the power of ten that scales trimmed to the value.
+	exp = exp - len(frac) + len(digits) - len(trimmed)
+	if exp < 0 || exp > len(trimmed)+maxExponentSlack {
+		return nil, false
+	}
+	b, _ := new(big.Int).SetString(trimmed, 10)
+	if exp > 0 {
+		b.Mul(b, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	}
+	if neg {
+		b.Neg(b)
+	}
+	return b, true
+}
+
+func isDecimalDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// jvToBigInt converts an integral numeric value to *big.Int without losing precision.
+func jvToBigInt(v interface{}) (*big.Int, bool) {
+	switch x := v.(type) {
+	case *big.Int:
+		if x == nil {
+			return nil, false
+		}
+		return new(big.Int).Set(x), true
+	case json.Number:
+		return decimalToBigInt(string(x))
+	case float32:
+		return jvToBigInt(float64(x))
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) || x != math.Trunc(x) {
+			return nil, false
+		}
+		b, _ := new(big.Float).SetFloat64(x).Int(nil)
+		return b, true
+	case uint, uint8, uint16, uint32, uint64:
+		n, _, _ := jvToUint64(x)
+		return new(big.Int).SetUint64(n), true
+	}
+	if n, _, ok := jvToInt64(v); ok {
+		return big.NewInt(n), true
+	}
+	return nil, false
+}
@@ -0,0 +1,134 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+const bigNumbersDoc = `{"id":9007199254740993,"neg":-9223372036854775808,"u":18446744073709551615,"huge":123456789012345678901234567890,"price":1.10,"exp":1e3,"tiny":1e-400}`
+
+func TestParseNumbers_JSONNumber(t *testing.T) {
+	j, ok := JSONFromStringWithOptions(bigNumbersDoc, ParseOptions{Numbers: NumberJSONNumber})
+	if !ok {
+		t.Fatalf("parse failed")
+	}
+	if _, isNum := j.GetByPath("price").Value.(json.Number); !isNum {
+		t.Fatalf("expected json.Number, got %T", j.GetByPath("price").Value)
+	}
+	want := `{"exp":1e3,"huge":123456789012345678901234567890,"id":9007199254740993,"neg":-9223372036854775808,"price":1.10,"tiny":1e-400,"u":18446744073709551615}`
+	if j.ToString() != want {
+		t.Fatalf("digits must round-trip:\n%s\n%s", j.ToString(), want)
+	}
+
+	ordered, _ := JSONFromStringWithOptions(bigNumbersDoc, ParseOptions{Numbers: NumberJSONNumber, PreserveKeyOrder: true})
+	if ordered.ToString() != bigNumbersDoc {
+		t.Fatalf("ordered lossless parse must reproduce the input: %s", ordered.ToString())
+	}
+
+	if n, ok := j.GetByPath("id").AsInt64(); !ok || n != 9007199254740993 {
+		t.Fatalf("AsInt64: %d %v", n, ok)
+	}
+	if n, ok := j.GetByPath("exp").AsInt64(); !ok || n != 1000 {
+		t.Fatalf("AsInt64 of 1e3: %d %v", n, ok)
+	}
+	if _, ok := j.GetByPath("price").AsInt64(); ok {
+		t.Fatalf("1.10 is not an integer")
+	}
+	if _, ok := j.GetByPath("u").AsInt64(); ok {
+		t.Fatalf("max uint64 must not fit int64")
+	}
+	if n, ok := j.GetByPath("u").AsUint64(); !ok || n != 18446744073709551615 {
+		t.Fatalf("AsUint64: %d %v", n, ok)
+	}
+	if _, ok := j.GetByPath("neg").AsUint64(); ok {
+		t.Fatalf("negative values must not convert to uint64")
+	}
+	b, ok := j.GetByPath("huge").AsBigInt()
+	if !ok || b.String() != "123456789012345678901234567890" {
+		t.Fatalf("AsBigInt: %v %v", b, ok)
+	}
+	if s, ok := j.GetByPath("price").AsDecimalString(); !ok || s != "1.10" {
+		t.Fatalf("AsDecimalString: %q %v", s, ok)
+	}
+	if f, ok := j.GetByPath("price").AsNumeric(); !ok || f != 1.1 {
+		t.Fatalf("AsNumeric on json.Number: %v %v", f, ok)
+	}
+}
+
+func TestParseNumbers_Typed(t *testing.T) {
+	j, ok := JSONFromStringWithOptions(bigNumbersDoc, ParseOptions{Numbers: NumberTyped})
+	if !ok {
+		t.Fatalf("parse failed")
+	}
+	types := map[string]interface{}{
+		"id":    int64(0),
+		"neg":   int64(0),
+		"u":     uint64(0),
+		"huge":  (*big.Int)(nil),
+		"price": float64(0),
+		"exp":   float64(0),
+		"tiny":  json.Number(""),
+	}
+	for k, want := range types {
+		got := j.GetByPath(k).Value
+		if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", want) {
+			t.Errorf("%s: got %T, want %T", k, got, want)
+		}
+	}
+	if j.GetByPath("huge").ToString() != "123456789012345678901234567890" {
+		t.Fatalf("big.Int must serialize as digits: %s", j.GetByPath("huge").ToString())
+	}
+	pretty, err := j.GetByPath("huge").ToBytesWith(SerializeOptions{})
+	if err != nil || string(pretty) != "123456789012345678901234567890" {
+		t.Fatalf("ToBytesWith of big.Int: %s %v", pretty, err)
+	}
+
+	v, err := GetAs[*big.Int](j, "id")
+	if err != nil || v.Int64() != 9007199254740993 {
+		t.Fatalf("GetAs[*big.Int]: %v %v", v, err)
+	}
+}
+
+func TestNumberEqualityIsExact(t *testing.T) {
+	a := NewJSON(json.Number("9007199254740993"))
+	b := NewJSON(int64(9007199254740992))
+	if len(Diff(a, b, DiffOptions{IgnoreNumberTypes: true})) == 0 {
+		t.Fatalf("integers above 2^53 must be compared exactly")
+	}
+	if len(Diff(a, NewJSON(int64(9007199254740993)), DiffOptions{IgnoreNumberTypes: true})) != 0 {
+		t.Fatalf("equal large integers must compare equal")
+	}
+}
+
+func TestJSONNumberToInteger(t *testing.T) {
+	for s, want := range map[string]string{
+		"15": "15", "1.5e1": "15", "120e-1": "12", "-0.0e5": "0", "-2E+3": "-2000",
+		"0.0001e4": "1", "1e40": "10000000000000000000000000000000000000000",
+		"1e400": "1" + strings.Repeat("0", 400), "12e399": "12" + strings.Repeat("0", 399),
+	} {
+		if b, ok := NewJSON(json.Number(s)).AsBigInt(); !ok || b.String() != want {
+			t.Errorf("%s: got %v, want %s", s, b, want)
+		}
+	}
+	for _, s := range []string{"1.25", "1e-1", "abc", "1e", ".5", "1e1000000", "1e500", "1e402", "-1e999999999999", "1e99999999999999999999"} {
+		if b, ok := NewJSON(json.Number(s)).AsBigInt(); ok {
+			t.Errorf("%s: expected no integer, got %v", s, b)
+		}
+	}
+
+	start := time.Now()
+	for _, s := range []string{"1e1000000", "1e100000000", "-7.5e999999999"} {
+		n := NewJSON(json.Number(s))
+		if _, ok := n.AsInt64(); ok {
+			t.Errorf("%s: AsInt64 must fail", s)
+		}
+		Diff(n, NewJSON(json.Number("1e1000001")), DiffOptions{IgnoreNumberTypes: true})
+	}
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Fatalf("huge exponents took %v", d)
+	}
+}
@@ -0,0 +1,245 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// OrderedObject is a JSON object that remembers the order in which its
+// members were added. It can be stored in JSON.Value wherever a
+// map[string]interface{} can: path operations, pointers, patches, merging,
+// queries and serialization all accept both, and members added to an ordered
+// object are appended after the existing ones.
+//
+// Use NewJSONOrderedObject or parse with ParseOptions.PreserveKeyOrder to
+// obtain ordered values.
+type OrderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedObject creates an empty ordered object.
+func NewOrderedObject() *OrderedObject {
+	return &OrderedObject{values: map[string]interface{}{}}
+}
+
+// NewJSONOrderedObject creates a new empty JSON object that preserves the
+// insertion order of its keys, including keys added later by SetByPath.
+func NewJSONOrderedObject() JSON {
+	return NewJSON(NewOrderedObject())
+}
+
+// Len returns the number of members.
+func (o *OrderedObject) Len() int {
+	return len(o.values)
+}
+
+// Keys returns the member keys in insertion order.
+func (o *OrderedObject) Keys() []string {
+	o.sync()
+	return append([]string(nil), o.keys...)
+}
+
+// Get returns the value of member key.
+func (o *OrderedObject) Get(key string) (interface{}, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// Set sets member key, appending it if it is new.
+func (o *OrderedObject) Set(key string, v interface{}) {
+	if o.values == nil {
+		o.values = map[string]interface{}{}
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+// Delete removes member key.
+func (o *OrderedObject) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Map returns the map backing the object. Members added directly to the map
+// bypass the ordering and are listed after the ordered ones, sorted by key.
+func (o *OrderedObject) Map() map[string]interface{} {
+	if o.values == nil {
+		o.values = map[string]interface{}{}
+	}
+	return o.values
+}
+
+// MarshalJSON writes the members in insertion order.
+func (o *OrderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.Keys() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		vb, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// sync reconciles the key list with the backing map after it was modified
+// directly through Map.
+func (o *OrderedObject) sync() {
+	if len(o.keys) == len(o.values) {
+		inSync := true
+		for _, k := range o.keys {
+			if _, ok := o.values[k]; !ok {
+				inSync = false
+				break
+			}
+		}
+		if inSync {
+			return
+		}
+	}
+	seen := make(map[string]bool, len(o.values))
+	keys := make([]string, 0, len(o.values))
+	for _, k := range o.keys {
+		if _, ok := o.values[k]; ok && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	var added []string
+	for k := range o.values {
+		if !seen[k] {
+			added = append(added, k)
+		}
+	}
+	sort.Strings(added)
+	o.keys = append(keys, added...)
+}
+
+// jvObject returns the members of an object node, plain or ordered.
+func jvObject(v interface{}) (map[string]interface{}, bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return x, true
+	case *OrderedObject:
+		if x == nil {
+			return nil, false
+		}
+		return x.Map(), true
+	}
+	return nil, false
+}
+
+// jvObjectKeys returns the keys of an object node: insertion order for
+// ordered objects, sorted order for plain maps.
+func jvObjectKeys(v interface{}) []string {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return sortedKeys(x)
+	case *OrderedObject:
+		if x != nil {
+			return x.Keys()
+		}
+	}
+	return nil
+}
+
+// jvObjectSet sets member key of an object node, keeping the order of
+// ordered objects.
+func jvObjectSet(obj interface{}, key string, v interface{}) {
+	switch x := obj.(type) {
+	case map[string]interface{}:
+		x[key] = v
+	case *OrderedObject:
+		x.Set(key, v)
+	}
+}
+
+// jvObjectDelete removes member key of an object node.
+func jvObjectDelete(obj interface{}, key string) {
+	switch x := obj.(type) {
+	case map[string]interface{}:
+		delete(x, key)
+	case *OrderedObject:
+		x.Delete(key)
+	}
+}
+
+// jvNewObjectLike returns an empty object of the same kind as like:
+// ordered if like is an ordered object, a plain map otherwise.
+func jvNewObjectLike(like interface{}) interface{} {
+	if _, ok := like.(*OrderedObject); ok {
+		return NewOrderedObject()
+	}
+	return map[string]interface{}{}
+}
+
+// jvDeepEqual is like reflect.DeepEqual but compares ordered and plain
+// objects by their members only.
+func jvDeepEqual(a, b interface{}) bool {
+	ao, aok := jvObject(a)
+	bo, bok := jvObject(b)
+	if aok || bok {
+		if !aok || !bok || len(ao) != len(bo) || (ao == nil) != (bo == nil) {
+			return false
+		}
+		for k, av := range ao {
+			bv, ok := bo[k]
+			if !ok || !jvDeepEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	aa, aok := a.([]interface{})
+	ba, bok := b.([]interface{})
+	if aok && bok {
+		if len(aa) != len(ba) || (aa == nil) != (ba == nil) {
+			return false
+		}
+		for i := range aa {
+			if !jvDeepEqual(aa[i], ba[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// ForEachKey calls fn for every member of an object in key order (insertion
+// order for ordered objects, sorted otherwise) until fn returns false.
+func (j JSON) ForEachKey(fn func(key string, value JSON) bool) {
+	obj, ok := jvObject(j.Value)
+	if !ok {
+		return
+	}
+	for _, k := range jvObjectKeys(j.Value) {
+		if !fn(k, NewJSON(obj[k])) {
+			return
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package easyjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustOrdered(t *testing.T, s string) JSON {
+	t.Helper()
+	j, ok := JSONFromStringWithOptions(s, ParseOptions{PreserveKeyOrder: true})
+	if !ok {
+		t.Fatalf("failed to parse %s", s)
+	}
+	return j
+}
+
+func TestOrderedParse(t *testing.T) {
+	src := `{"z":1,"a":{"y":true,"b":null},"m":[{"k2":1,"k1":2}],"a2":"x"}`
+	j := mustOrdered(t, src)
+
+	if got := j.ObjectKeys(); !reflect.DeepEqual(got, []string{"z", "a", "m", "a2"}) {
+		t.Fatalf("unexpected key order %v", got)
+	}
+	if j.ToString() != src {
+		t.Fatalf("ToBytes must keep the input order:\n%s\n%s", j.ToString(), src)
+	}
+	if j.GetByPath("m.0.k1").AsNumericDefault(0) != 2 || !j.PathExists("a.b") {
+		t.Fatalf("path access on ordered objects failed")
+	}
+	if !j.Equals(mustJSONFromString(t, src)) {
+		t.Fatalf("ordered and plain objects with the same members must be equal")
+	}
+
+	dup := mustOrdered(t, `{"a":1,"b":2,"a":3}`)
+	if dup.ToString() != `{"a":3,"b":2}` {
+		t.Fatalf("duplicate keys: %s", dup.ToString())
+	}
+	for _, bad := range []string{`{"a":1`, `{"a":1} x`, `[1,]`, ``} {
+		if _, ok := JSONFromStringWithOptions(bad, ParseOptions{PreserveKeyOrder: true}); ok {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestOrderedSetAndRemove(t *testing.T) {
+	j := NewJSONOrderedObject()
+	j.SetByPath("name", NewJSON("svc"))
+	j.SetByPath("spec.replicas", NewJSON(3))
+	j.SetByPath("spec.image", NewJSON("nginx"))
+	j.SetByPath("api", NewJSON("v1"))
+	j.SetByPath("name", NewJSON("svc2"))
+
+	if j.ToString() != `{"name":"svc2","spec":{"replicas":3,"image":"nginx"},"api":"v1"}` {
+		t.Fatalf("unexpected order: %s", j.ToString())
+	}
+
+	j.RemoveByPath("spec.replicas")
+	j.SetByPath("spec.replicas", NewJSON(5))
+	if got := j.GetByPath("spec").ObjectKeys(); !reflect.DeepEqual(got, []string{"image", "replicas"}) {
+		t.Fatalf("re-added key must move to the end: %v", got)
+	}
+
+	var keys []string
+	j.ForEachKey(func(k string, v JSON) bool {
+		keys = append(keys, k)
+		return k != "spec"
+	})
+	if !reflect.DeepEqual(keys, []string{"name", "spec"}) {
+		t.Fatalf("ForEachKey must stop when fn returns false: %v", keys)
+	}
+
+	c := j.Clone()
+	c.SetByPath("z", NewJSON(1))
+	if j.PathExists("z") || c.ToString() != `{"name":"svc2","spec":{"image":"nginx","replicas":5},"api":"v1","z":1}` {
+		t.Fatalf("Clone must keep order and be independent: %s", c.ToString())
+	}
+
+	// Members added through the backing map are listed after the ordered ones.
+	m, _ := j.AsObject()
+	m["c"], m["b"] = 1, 2
+	if got := j.ObjectKeys(); !reflect.DeepEqual(got, []string{"name", "spec", "api", "b", "c"}) {
+		t.Fatalf("unexpected keys after direct map writes: %v", got)
+	}
+}
+
+func TestOrderedOperations(t *testing.T) {
+	j := mustOrdered(t, `{"b":1,"a":{"y":1,"x":2}}`)
+
+	j.DeepMerge(mustOrdered(t, `{"d":1,"c":2,"a":{"w":3}}`))
+	if j.ToString() != `{"b":1,"a":{"y":1,"x":2,"w":3},"d":1,"c":2}` {
+		t.Fatalf("DeepMerge order: %s", j.ToString())
+	}
+
+	j.MergePatch(mustOrdered(t, `{"d":null,"e":{"q":1,"p":2}}`))
+	if j.ToString() != `{"b":1,"a":{"y":1,"x":2,"w":3},"c":2,"e":{"q":1,"p":2}}` {
+		t.Fatalf("MergePatch order: %s", j.ToString())
+	}
+
+	p, err := ParsePatch([]byte(`[{"op":"add","path":"/a/v","value":0},{"op":"move","from":"/b","path":"/f"}]`))
+	if err != nil {
+		t.Fatalf("ParsePatch failed: %v", err)
+	}
+	if err := j.ApplyPatch(p); err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	if j.ToString() != `{"a":{"y":1,"x":2,"w":3,"v":0},"c":2,"e":{"q":1,"p":2},"f":1}` {
+		t.Fatalf("ApplyPatch order: %s", j.ToString())
+	}
+
+	matches, _ := j.Query("$.a.*")
+	var got []string
+	for _, m := range matches {
+		got = append(got, m.Path)
+	}
+	if !reflect.DeepEqual(got, []string{"$['a']['y']", "$['a']['x']", "$['a']['w']", "$['a']['v']"}) {
+		t.Fatalf("JSONPath must visit ordered members in order: %v", got)
+	}
+
+	pretty, _ := j.ToBytesWith(SerializeOptions{})
+	if string(pretty) != j.ToString() {
+		t.Fatalf("ToBytesWith must keep the order: %s", pretty)
+	}
+	sorted, _ := j.ToBytesWith(SerializeOptions{SortKeys: true})
+	if string(sorted) != `{"a":{"v":0,"w":3,"x":2,"y":1},"c":2,"e":{"p":2,"q":1},"f":1}` {
+		t.Fatalf("SortKeys output: %s", sorted)
+	}
+	canon, _ := j.ToCanonicalBytes()
+	if string(canon) != string(sorted) {
+		t.Fatalf("canonical form must ignore insertion order: %s", canon)
+	}
+
+	var target struct {
+		A map[string]int `json:"a"`
+		C int            `json:"c"`
+	}
+	if err := j.Decode("", &target); err != nil || target.A["w"] != 3 || target.C != 2 {
+		t.Fatalf("Decode from ordered object failed: %+v %v", target, err)
+	}
+}
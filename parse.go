@@ -0,0 +1,614 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ParseOptions configures ParseJSON and JSONFromBytesWithOptions.
+type ParseOptions struct {
+	// PreserveKeyOrder parses objects into *OrderedObject values that keep
+	// the key order of the input through ObjectKeys, ForEachKey and ToBytes.
+	PreserveKeyOrder bool
+	// Numbers selects the Go representation of numbers; the default is float64.
+	Numbers NumberMode
+
+	// Limits for untrusted input; zero means no limit. MaxDepth defaults to
+	// 10000 nested objects and arrays, like encoding/json. MaxStringLength
+	// applies to decoded strings and object keys, in bytes.
+	MaxDepth         int
+	MaxBytes         int
+	MaxStringLength  int
+	MaxArrayElements int
+	MaxObjectMembers int
+
+	// DuplicateKeys selects what happens when an object repeats a key.
+	DuplicateKeys DuplicateKeyPolicy
+
+	// Syntax selects the accepted input dialect; the default is strict JSON.
+	Syntax Syntax
+	// Comments, when not nil, collects the comments of JSONC and JSON5 input
+	// keyed by the JSON Pointer of the value they belong to.
+	Comments *Comments
+}
+
+// DuplicateKeyPolicy selects how ParseJSON treats repeated object keys.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeyLastWins keeps the last value, like encoding/json.
+	DuplicateKeyLastWins DuplicateKeyPolicy = iota
+	// DuplicateKeyFirstWins keeps the first value and ignores later ones.
+	DuplicateKeyFirstWins
+	// DuplicateKeyError fails with ErrDuplicateKey.
+	DuplicateKeyError
+)
+
+const defaultMaxDepth = 10000
+
+// ParseError describes where and why parsing failed.
+// Offset is the byte offset of the offending input, Line and Column its
+// 1-based position (columns count characters). Snippet is the surrounding
+// part of the offending line and Expected, when known, what the parser
+// was looking for at that point.
+// Err is ErrLimitExceeded or ErrDuplicateKey when the input was rejected
+// by a ParseOptions safeguard, and nil for syntax errors.
+type ParseError struct {
+	Offset   int
+	Line     int
+	Column   int
+	Snippet  string
+	Expected string
+	Msg      string
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("easyjson: parse error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+	if e.Expected != "" {
+		msg += ", expected " + e.Expected
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseJSON parses b as a single JSON document. On failure it returns a
+// *ParseError locating the problem.
+func ParseJSON(b []byte, opts ...ParseOptions) (JSON, error) {
+	p := &parser{data: b}
+	if len(opts) > 0 {
+		p.opts = opts[0]
+	}
+	if p.opts.MaxDepth <= 0 {
+		p.opts.MaxDepth = defaultMaxDepth
+	}
+	if p.opts.MaxBytes > 0 && len(b) > p.opts.MaxBytes {
+		err := newParseError(b, p.opts.MaxBytes, "", fmt.Sprintf("input exceeds %d bytes", p.opts.MaxBytes))
+		err.Err = ErrLimitExceeded
+		return NewJSONNull(), err
+	}
+	v, err := p.parse()
+	if err != nil {
+		return NewJSONNull(), err
+	}
+	return NewJSON(v), nil
+}
+
+// ParseJSONString is like ParseJSON for string input.
+func ParseJSONString(s string, opts ...ParseOptions) (JSON, error) {
+	return ParseJSON([]byte(s), opts...)
+}
+
+// JSONFromBytesWithOptions is like JSONFromBytes but parses according to opts.
+func JSONFromBytesWithOptions(b []byte, opts ParseOptions) (JSON, bool) {
+	if opts == (ParseOptions{}) {
+		return JSONFromBytes(b)
+	}
+	j, err := ParseJSON(b, opts)
+	return j, err == nil
+}
+
+// JSONFromStringWithOptions is like JSONFromString but parses according to opts.
+func JSONFromStringWithOptions(s string, opts ParseOptions) (JSON, bool) {
+	return JSONFromBytesWithOptions([]byte(s), opts)
+}
+
+// parser is a recursive descent JSON parser that builds the value tree
+// directly and reports precise error locations.
+type parser struct {
+	data  []byte
+	pos   int
+	opts  ParseOptions
+	depth int
+
+	// Comment collection state, see relaxed.go.
+	path    []string
+	pending []Comment
+	last    string
+	hasLast bool
+	newline bool
+}
+
+func (p *parser) parse() (interface{}, error) {
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.data) {
+		return nil, p.errorf("end of input", "unexpected %s after top-level value", p.describe())
+	}
+	p.endDocument()
+	return v, nil
+}
+
+func (p *parser) errorf(expected, format string, args ...interface{}) *ParseError {
+	return newParseError(p.data, p.pos, expected, fmt.Sprintf(format, args...))
+}
+
+// limitError reports input at offset rejected by a ParseOptions safeguard.
+func (p *parser) limitError(offset int, sentinel error, format string, args ...interface{}) *ParseError {
+	err := newParseError(p.data, offset, "", fmt.Sprintf(format, args...))
+	err.Err = sentinel
+	return err
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > p.opts.MaxDepth {
+		return p.limitError(p.pos, ErrLimitExceeded, "nesting depth exceeds %d", p.opts.MaxDepth)
+	}
+	return nil
+}
+
+func newParseError(data []byte, offset int, expected, msg string) *ParseError {
+	lineStart := 0
+	line := 1
+	for i := 0; i < offset && i < len(data); i++ {
+		if data[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	lineEnd := lineStart
+	for lineEnd < len(data) && data[lineEnd] != '\n' && data[lineEnd] != '\r' {
+		lineEnd++
+	}
+	end := offset
+	if end > len(data) {
+		end = len(data)
+	}
+	col := utf8.RuneCount(data[lineStart:end]) + 1
+
+	// Show up to snippetRadius bytes around the error on its line.
+	const snippetRadius = 30
+	from, to := lineStart, lineEnd
+	if end-from > snippetRadius {
+		from = end - snippetRadius
+	}
+	if to-end > snippetRadius {
+		to = end + snippetRadius
+	}
+	for from > lineStart && !utf8.RuneStart(data[from]) {
+		from--
+	}
+	for to < lineEnd && !utf8.RuneStart(data[to]) {
+		to++
+	}
+	return &ParseError{
+		Offset:   offset,
+		Line:     line,
+		Column:   col,
+		Snippet:  string(data[from:to]),
+		Expected: expected,
+		Msg:      msg,
+	}
+}
+
+// describe names the input at the current position for error messages.
+func (p *parser) describe() string {
+	if p.pos >= len(p.data) {
+		return "end of input"
+	}
+	r, _ := utf8.DecodeRune(p.data[p.pos:])
+	return fmt.Sprintf("character %q", r)
+}
+
+func (p *parser) skipSpace() error {
+	if p.opts.Syntax != SyntaxJSON {
+		return p.skipRelaxed()
+	}
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *parser) value() (v interface{}, err error) {
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("value", "unexpected end of input")
+	}
+	if p.opts.Comments != nil {
+		p.beginValue()
+		defer func() {
+			if err == nil {
+				p.endValue()
+			}
+		}()
+	}
+	if p.opts.Syntax == SyntaxJSON5 {
+		if v, ok, err := p.value5(); ok {
+			return v, err
+		}
+	}
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		s, err := p.string()
+		return s, err
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	case c == 't':
+		return true, p.literal("true")
+	case c == 'f':
+		return false, p.literal("false")
+	case c == 'n':
+		return nil, p.literal("null")
+	}
+	return nil, p.errorf("value", "unexpected %s", p.describe())
+}
+
+func (p *parser) literal(lit string) error {
+	for i := 0; i < len(lit); i++ {
+		if p.pos >= len(p.data) {
+			return p.errorf(strconv.Quote(lit), "unexpected end of input")
+		}
+		if p.data[p.pos] != lit[i] {
+			return p.errorf(strconv.Quote(lit), "unexpected %s", p.describe())
+		}
+		p.pos++
+	}
+	return nil
+}
+
+func (p *parser) object() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	p.pos++ // '{'
+	var obj interface{} = map[string]interface{}{}
+	if p.opts.PreserveKeyOrder {
+		obj = NewOrderedObject()
+	}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		p.endContainer()
+		return obj, nil
+	}
+	members, _ := jvObject(obj)
+	for {
+		keyPos := p.pos
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		_, dup := members[key]
+		if dup && p.opts.DuplicateKeys == DuplicateKeyError {
+			return nil, p.limitError(keyPos, ErrDuplicateKey, "duplicate object key %q", key)
+		}
+		if !dup && p.opts.MaxObjectMembers > 0 && len(members) >= p.opts.MaxObjectMembers {
+			return nil, p.limitError(keyPos, ErrLimitExceeded, "object has more than %d members", p.opts.MaxObjectMembers)
+		}
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return nil, p.errorf("':'", "unexpected %s after object key", p.describe())
+		}
+		p.pos++
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		p.enterMember(key)
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		p.leave()
+		if !dup || p.opts.DuplicateKeys == DuplicateKeyLastWins {
+			jvObjectSet(obj, key, v)
+		}
+
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("',' or '}'", "unexpected end of input")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			if err := p.skipSpace(); err != nil {
+				return nil, err
+			}
+			if !p.trailingComma('}') {
+				continue
+			}
+		case '}':
+		default:
+			return nil, p.errorf("',' or '}'", "unexpected %s after object member", p.describe())
+		}
+		p.pos++
+		p.endContainer()
+		return obj, nil
+	}
+}
+
+// key reads an object key.
+func (p *parser) key() (string, error) {
+	if p.opts.Syntax == SyntaxJSON5 {
+		return p.key5()
+	}
+	if p.pos >= len(p.data) || p.data[p.pos] != '"' {
+		return "", p.errorf("object key", "unexpected %s", p.describe())
+	}
+	return p.string()
+}
+
+func (p *parser) array() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	p.pos++ // '['
+	arr := []interface{}{}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		p.endContainer()
+		return arr, nil
+	}
+	for {
+		if p.opts.MaxArrayElements > 0 && len(arr) >= p.opts.MaxArrayElements {
+			return nil, p.limitError(p.pos, ErrLimitExceeded, "array has more than %d elements", p.opts.MaxArrayElements)
+		}
+		p.enterElement(len(arr))
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		p.leave()
+		arr = append(arr, v)
+
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("',' or ']'", "unexpected end of input")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			if err := p.skipSpace(); err != nil {
+				return nil, err
+			}
+			if !p.trailingComma(']') {
+				continue
+			}
+		case ']':
+		default:
+			return nil, p.errorf("',' or ']'", "unexpected %s after array element", p.describe())
+		}
+		p.pos++
+		p.endContainer()
+		return arr, nil
+	}
+}
+
+func (p *parser) number() (interface{}, error) {
+	start := p.pos
+	digits := func() int {
+		n := 0
+		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			p.pos++
+			n++
+		}
+		return n
+	}
+
+	if p.data[p.pos] == '-' {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '0' {
+		p.pos++
+	} else if digits() == 0 {
+		return nil, p.errorf("digit", "unexpected %s in number", p.describe())
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		if digits() == 0 {
+			return nil, p.errorf("digit", "unexpected %s after decimal point", p.describe())
+		}
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if digits() == 0 {
+			return nil, p.errorf("digit", "unexpected %s in exponent", p.describe())
+		}
+	}
+
+	text := p.data[start:p.pos]
+	v, err := parseNumber(json.Number(text), p.opts.Numbers)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("", "number %s out of range", text)
+	}
+	return v, nil
+}
+
+func (p *parser) string() (string, error) {
+	start := p.pos
+	s, err := p.scanString()
+	if err == nil && p.opts.MaxStringLength > 0 && len(s) > p.opts.MaxStringLength {
+		return "", p.limitError(start, ErrLimitExceeded, "string longer than %d bytes", p.opts.MaxStringLength)
+	}
+	return s, err
+}
+
+func (p *parser) scanString() (string, error) {
+	quote := p.data[p.pos] // '"', or '\'' in JSON5
+	p.pos++
+	start := p.pos
+
+	// Fast path: no escapes and valid UTF-8.
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == quote {
+			s := p.data[start:p.pos]
+			if utf8.Valid(s) {
+				p.pos++
+				return string(s), nil
+			}
+			break
+		}
+		if c == '\\' || c < 0x20 {
+			break
+		}
+		p.pos++
+	}
+
+	var sb strings.Builder
+	sb.Write(p.data[start:p.pos])
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorf("'\"'", "unterminated string")
+		}
+		c := p.data[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c < 0x20 && !(c == '\t' && p.opts.Syntax == SyntaxJSON5):
+			return "", p.errorf("'\"'", "control character %q in string", c)
+		case c == '\\':
+			if err := p.escape(&sb); err != nil {
+				return "", err
+			}
+		case c < utf8.RuneSelf:
+			sb.WriteByte(c)
+			p.pos++
+		default:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			sb.WriteRune(r) // invalid UTF-8 becomes U+FFFD, as in encoding/json
+			p.pos += size
+		}
+	}
+}
+
+func (p *parser) escape(sb *strings.Builder) error {
+	p.pos++ // backslash
+	if p.pos >= len(p.data) {
+		return p.errorf("escape sequence", "unterminated string")
+	}
+	c := p.data[p.pos]
+	switch c {
+	case '"', '\\', '/':
+		sb.WriteByte(c)
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'u':
+		r, err := p.hex(4)
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(r) {
+			// A high surrogate must be followed by an escaped low surrogate.
+			r2 := utf8.RuneError
+			if p.pos+2 < len(p.data) && p.data[p.pos+1] == '\\' && p.data[p.pos+2] == 'u' {
+				save := p.pos
+				p.pos += 2
+				low, err := p.hex(4)
+				if err != nil {
+					return err
+				}
+				if dec := utf16.DecodeRune(r, low); dec != utf8.RuneError {
+					r2 = dec
+				} else {
+					p.pos = save
+				}
+			}
+			r = r2
+		}
+		sb.WriteRune(r)
+	default:
+		if p.opts.Syntax == SyntaxJSON5 {
+			return p.escape5(sb)
+		}
+		return p.errorf("escape sequence", "invalid escape %q", "\\"+string(rune(c)))
+	}
+	p.pos++
+	return nil
+}
+
+// hex reads the n hex digits of a \u or \x escape; p.pos is left on the
+// last digit.
+func (p *parser) hex(n int) (rune, error) {
+	var r rune
+	for i := 0; i < n; i++ {
+		p.pos++
+		if p.pos >= len(p.data) {
+			return 0, p.errorf("hex digit", "unterminated string")
+		}
+		c := p.data[p.pos]
+		var d byte
+		switch {
+		case c >= '0' && c <= '9':
+			d = c - '0'
+		case c >= 'a' && c <= 'f':
+			d = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			d = c - 'A' + 10
+		default:
+			return 0, p.errorf("hex digit", "unexpected %s in escape sequence", p.describe())
+		}
+		r = r<<4 | rune(d)
+	}
+	return r, nil
+}
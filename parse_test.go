@@ -0,0 +1,144 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSON_MatchesEncodingJSON(t *testing.T) {
+	inputs := []string{
+		`{"a":[1,-2.5e3,0,true,false,null],"b":{"c":"x\"y\\z\/\b\f\n\r\t"},"d":[]}`,
+		` "é😀 \ud800 \udc00x" `,
+		`"invalid utf8 \xff here"`,
+		`-0.0e-0`,
+		`[{}, [], "", 0]`,
+		`{"dup":1,"dup":2}`,
+		``, `{`, `[1,]`, `{"a" 1}`, `{"a":1,}`, `01`, `1.`, `1e`, `-`, `tru`, `nul`, `"abc`,
+		`"\x01"`, `"\q"`, `"\u12"`, `[1 2]`, `{} {}`, `{1:2}`, `1e400`,
+	}
+	for _, in := range inputs {
+		var want interface{}
+		wantErr := json.Unmarshal([]byte(in), &want)
+		got, err := ParseJSONString(in)
+		if (err != nil) != (wantErr != nil) {
+			t.Errorf("%q: error mismatch: got %v, encoding/json %v", in, err, wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got.Value, want) {
+			t.Errorf("%q: got %#v, want %#v", in, got.Value, want)
+		}
+	}
+}
+
+func TestParseJSON_ErrorLocation(t *testing.T) {
+	src := "{\n  \"name\": \"svc\",\n  \"port\": 80\n  \"host\": \"x\"\n}"
+	_, err := ParseJSONString(src)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *ParseError, got %v", err)
+	}
+	if perr.Line != 4 || perr.Column != 3 || perr.Offset != 34 {
+		t.Fatalf("unexpected location %d:%d (offset %d)", perr.Line, perr.Column, perr.Offset)
+	}
+	if perr.Expected != "',' or '}'" || perr.Snippet != `  "host": "x"` {
+		t.Fatalf("unexpected details: expected=%q snippet=%q", perr.Expected, perr.Snippet)
+	}
+
+	_, err = ParseJSONString(`{"ключ": tru}`)
+	if !errors.As(err, &perr) || perr.Column != 13 || perr.Expected != `"true"` {
+		t.Fatalf("columns must count characters: %+v", perr)
+	}
+
+	_, err = ParseJSONString(`[1, 2`)
+	if !errors.As(err, &perr) || perr.Offset != 5 || perr.Msg != "unexpected end of input" {
+		t.Fatalf("unexpected end of input error: %+v", perr)
+	}
+	if perr.Error() != "easyjson: parse error at line 1, column 6: unexpected end of input, expected ',' or ']'" {
+		t.Fatalf("unexpected message: %s", perr.Error())
+	}
+}
+
+func TestParseJSON_Snippet(t *testing.T) {
+	long := `{"padding": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "bad": x, "more": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}`
+	_, err := ParseJSONString(long)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *ParseError, got %v", err)
+	}
+	if len(perr.Snippet) != 60 || perr.Snippet[30] != 'x' {
+		t.Fatalf("snippet must be centered on the error: %q", perr.Snippet)
+	}
+}
+
+func TestParseJSON_Options(t *testing.T) {
+	j, err := ParseJSONString(`{"b":1,"a":12345678901234567890}`, ParseOptions{PreserveKeyOrder: true, Numbers: NumberJSONNumber})
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	if j.ToString() != `{"b":1,"a":12345678901234567890}` {
+		t.Fatalf("options not applied: %s", j.ToString())
+	}
+}
+
+func TestParseJSON_Limits(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		opts   ParseOptions
+		offset int
+	}{
+		{"depth", `{"a":[[1]]}`, ParseOptions{MaxDepth: 2}, 6},
+		{"bytes", `[1,2,3]`, ParseOptions{MaxBytes: 5}, 5},
+		{"string", `{"k":"abcdef"}`, ParseOptions{MaxStringLength: 5}, 5},
+		{"key", `{"abcdef":1}`, ParseOptions{MaxStringLength: 5}, 1},
+		{"array", `[1,2,3]`, ParseOptions{MaxArrayElements: 2}, 5},
+		{"object", `{"a":1,"b":2,"c":3}`, ParseOptions{MaxObjectMembers: 2}, 13},
+	}
+	for _, c := range cases {
+		_, err := ParseJSONString(c.input, c.opts)
+		var perr *ParseError
+		if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &perr) || perr.Offset != c.offset {
+			t.Errorf("%s: expected limit error at offset %d, got %v", c.name, c.offset, err)
+		}
+	}
+
+	ok := ParseOptions{MaxDepth: 3, MaxBytes: 100, MaxStringLength: 6, MaxArrayElements: 3, MaxObjectMembers: 2}
+	if _, err := ParseJSONString(`{"a":[[1,2,3]],"b":"abcdef"}`, ok); err != nil {
+		t.Fatalf("input within limits rejected: %v", err)
+	}
+
+	deep := make([]byte, 0, 2*(defaultMaxDepth+1))
+	for i := 0; i <= defaultMaxDepth; i++ {
+		deep = append(deep, '[')
+	}
+	for i := 0; i <= defaultMaxDepth; i++ {
+		deep = append(deep, ']')
+	}
+	if _, err := ParseJSON(deep); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("default depth limit not applied: %v", err)
+	}
+}
+
+func TestParseJSON_DuplicateKeys(t *testing.T) {
+	src := `{"a":1,"b":2,"a":3}`
+
+	j, _ := ParseJSONString(src)
+	if j.GetByPath("a").AsNumericDefault(0) != 3 {
+		t.Fatalf("last value must win by default")
+	}
+	j, _ = ParseJSONString(src, ParseOptions{DuplicateKeys: DuplicateKeyFirstWins, PreserveKeyOrder: true})
+	if j.ToString() != `{"a":1,"b":2}` {
+		t.Fatalf("first value must win: %s", j.ToString())
+	}
+	_, err := ParseJSONString(src, ParseOptions{DuplicateKeys: DuplicateKeyError})
+	var perr *ParseError
+	if !errors.Is(err, ErrDuplicateKey) || !errors.As(err, &perr) || perr.Offset != 13 {
+		t.Fatalf("expected duplicate key error at offset 13, got %v", err)
+	}
+	// Duplicates do not count against MaxObjectMembers.
+	if _, err := ParseJSONString(src, ParseOptions{MaxObjectMembers: 2}); err != nil {
+		t.Fatalf("duplicate key counted as a new member: %v", err)
+	}
+}
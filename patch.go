@@ -0,0 +1,350 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RFC 6902 JSON Patch support.
+
+// Patch operation names.
+const (
+	PatchOpAdd     = "add"
+	PatchOpRemove  = "remove"
+	PatchOpReplace = "replace"
+	PatchOpMove    = "move"
+	PatchOpCopy    = "copy"
+	PatchOpTest    = "test"
+)
+
+// PatchOperation is a single JSON Patch operation.
+// Path and From are JSON Pointers; Value is used by add, replace and test.
+type PatchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+// Patch is an ordered list of JSON Patch operations.
+type Patch []PatchOperation
+
+// PatchError reports the operation that caused a patch to fail.
+type PatchError struct {
+	Index int
+	Op    PatchOperation
+	Msg   string
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("patch operation %d (%s %s): %s", e.Index, e.Op.Op, e.Op.Path, e.Msg)
+}
+
+// MarshalJSON encodes the operation with only the members its op uses.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{"op": op.Op, "path": op.Path}
+	switch op.Op {
+	case PatchOpAdd, PatchOpReplace, PatchOpTest:
+		m["value"] = op.Value
+	case PatchOpMove, PatchOpCopy:
+		m["from"] = op.From
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes and validates a single operation object.
+func (op *PatchOperation) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	str := func(name string) (string, error) {
+		v, ok := raw[name]
+		if !ok {
+			return "", fmt.Errorf("patch operation is missing %q", name)
+		}
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return "", fmt.Errorf("patch operation member %q must be a string", name)
+		}
+		return s, nil
+	}
+
+	var res PatchOperation
+	var err error
+	if res.Op, err = str("op"); err != nil {
+		return err
+	}
+	if res.Path, err = str("path"); err != nil {
+		return err
+	}
+	switch res.Op {
+	case PatchOpAdd, PatchOpReplace, PatchOpTest:
+		v, ok := raw["value"]
+		if !ok {
+			return fmt.Errorf("%s operation is missing \"value\"", res.Op)
+		}
+		if err := json.Unmarshal(v, &res.Value); err != nil {
+			return err
+		}
+	case PatchOpMove, PatchOpCopy:
+		if res.From, err = str("from"); err != nil {
+			return err
+		}
+	case PatchOpRemove:
+	default:
+		return fmt.Errorf("unknown patch operation %q", res.Op)
+	}
+	*op = res
+	return nil
+}
+
+// ParsePatch decodes a JSON Patch document.
+func ParsePatch(b []byte) (Patch, error) {
+	var p Patch
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ToBytes serializes the patch as a JSON Patch document.
+func (p Patch) ToBytes() []byte {
+	if p == nil {
+		p = Patch{}
+	}
+	bytes, _ := json.Marshal(p)
+	return bytes
+}
+
+// ApplyPatch applies all operations of p in order. The patch is atomic:
+// if any operation fails j is left untouched and a *PatchError
+// identifying the failing operation is returned.
+func (j *JSON) ApplyPatch(p Patch) error {
+	doc := deepCopy(j.Value)
+	for i, op := range p {
+		if msg := jvApplyPatchOperation(&doc, op); msg != "" {
+			return &PatchError{Index: i, Op: op, Msg: msg}
+		}
+	}
+	j.Value = doc
+	return nil
+}
+
+// jvApplyPatchOperation applies a single operation and returns a failure description, if any.
+func jvApplyPatchOperation(doc *interface{}, op PatchOperation) string {
+	path, ok := ParsePointer(op.Path)
+	if !ok {
+		return "invalid path"
+	}
+
+	switch op.Op {
+	case PatchOpAdd:
+		return jvPatchAdd(doc, path, deepCopy(op.Value))
+
+	case PatchOpRemove:
+		if _, ok := jvRemoveByTokens(doc, path); !ok {
+			return "path does not exist"
+		}
+
+	case PatchOpReplace:
+		if _, ok := jvGetByTokens(*doc, path); !ok {
+			return "path does not exist"
+		}
+		jvSetByTokens(doc, path, deepCopy(op.Value), false)
+
+	case PatchOpMove:
+		from, ok := ParsePointer(op.From)
+		if !ok {
+			return "invalid from"
+		}
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return "cannot move a value into one of its children"
+		}
+		v, ok := jvRemoveByTokens(doc, from)
+		if !ok {
+			return "from does not exist"
+		}
+		return jvPatchAdd(doc, path, v)
+
+	case PatchOpCopy:
+		from, ok := ParsePointer(op.From)
+		if !ok {
+			return "invalid from"
+		}
+		v, ok := jvGetByTokens(*doc, from)
+		if !ok {
+			return "from does not exist"
+		}
+		return jvPatchAdd(doc, path, deepCopy(v))
+
+	case PatchOpTest:
+		v, ok := jvGetByTokens(*doc, path)
+		if !ok {
+			return "path does not exist"
+		}
+		if !jvEqual(v, op.Value) {
+			return "test failed"
+		}
+
+	default:
+		return fmt.Sprintf("unknown operation %q", op.Op)
+	}
+	return ""
+}
+
+// jvPatchAdd implements "add": the parent must already exist and array
+// elements are inserted rather than replaced.
+func jvPatchAdd(doc *interface{}, path []string, v interface{}) string {
+	if len(path) > 0 {
+		parent, ok := jvGetByTokens(*doc, path[:len(path)-1])
+		if !ok {
+			return "parent does not exist"
+		}
+		if !jvIsContainer(parent) {
+			return "parent is not an object or array"
+		}
+	}
+	if !jvSetByTokens(doc, path, v, true) {
+		return "invalid array index"
+	}
+	return ""
+}
+
+// CreatePatch generates a patch that transforms from into to.
+// Objects are compared member by member and arrays element by element
+// (using their longest common subsequence), so unchanged parts of the
+// document produce no operations.
+func CreatePatch(from, to JSON) Patch {
+	p := Patch{}
+	jvDiffPatch(&p, nil, from.Value, to.Value)
+	return p
+}
+
+// lcsMaxCells bounds the LCS table size used when diffing arrays.
+const lcsMaxCells = 1 << 20
+
+func jvDiffPatch(p *Patch, path []string, a, b interface{}) {
+	if jvEqual(a, b) {
+		return
+	}
+	if av, ok := jvObject(a); ok {
+		if bv, ok := jvObject(b); ok {
+			for _, k := range jvObjectKeys(a) {
+				if _, ok := bv[k]; !ok {
+					*p = append(*p, PatchOperation{Op: PatchOpRemove, Path: BuildPointer(append(path[:len(path):len(path)], k)...)})
+				}
+			}
+			for _, k := range jvObjectKeys(b) {
+				child := append(path[:len(path):len(path)], k)
+				if old, ok := av[k]; ok {
+					jvDiffPatch(p, child, old, bv[k])
+				} else {
+					*p = append(*p, PatchOperation{Op: PatchOpAdd, Path: BuildPointer(child...), Value: deepCopy(bv[k])})
+				}
+			}
+			return
+		}
+	}
+	if av, ok := a.([]interface{}); ok {
+		if bv, ok := b.([]interface{}); ok {
+			jvDiffPatchArray(p, path, av, bv)
+			return
+		}
+	}
+	*p = append(*p, PatchOperation{Op: PatchOpReplace, Path: BuildPointer(path...), Value: deepCopy(b)})
+}
+
+func jvDiffPatchArray(p *Patch, path []string, a, b []interface{}) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && jvEqual(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && jvEqual(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// keep[i] is the index in bm matched with am[i], or -1.
+	keep := make([]int, len(am))
+	for i := range keep {
+		keep[i] = -1
+	}
+	if len(am)*len(bm) <= lcsMaxCells {
+		lcs := make([][]int, len(am)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(bm)+1)
+		}
+		for i := len(am) - 1; i >= 0; i-- {
+			for k := len(bm) - 1; k >= 0; k-- {
+				switch {
+				case jvEqual(am[i], bm[k]):
+					lcs[i][k] = lcs[i+1][k+1] + 1
+				case lcs[i+1][k] >= lcs[i][k+1]:
+					lcs[i][k] = lcs[i+1][k]
+				default:
+					lcs[i][k] = lcs[i][k+1]
+				}
+			}
+		}
+		for i, k := 0, 0; i < len(am) && k < len(bm); {
+			switch {
+			case jvEqual(am[i], bm[k]):
+				keep[i] = k
+				i++
+				k++
+			case lcs[i+1][k] >= lcs[i][k+1]:
+				i++
+			default:
+				k++
+			}
+		}
+	}
+
+	at := func(pos int) []string {
+		return append(path[:len(path):len(path)], strconv.Itoa(pos))
+	}
+	pos := prefix
+	i, k := 0, 0
+	for i <= len(am) {
+		// Collect the run of removed and inserted elements before the next kept one.
+		var dels []interface{}
+		for i < len(am) && keep[i] < 0 {
+			dels = append(dels, am[i])
+			i++
+		}
+		next := len(bm)
+		if i < len(am) {
+			next = keep[i]
+		}
+		ins := bm[k:next]
+		k = next
+
+		pairs := len(dels)
+		if len(ins) < pairs {
+			pairs = len(ins)
+		}
+		for n := 0; n < pairs; n++ {
+			jvDiffPatch(p, at(pos), dels[n], ins[n])
+			pos++
+		}
+		for n := pairs; n < len(dels); n++ {
+			*p = append(*p, PatchOperation{Op: PatchOpRemove, Path: BuildPointer(at(pos)...)})
+		}
+		for n := pairs; n < len(ins); n++ {
+			*p = append(*p, PatchOperation{Op: PatchOpAdd, Path: BuildPointer(at(pos)...), Value: deepCopy(ins[n])})
+			pos++
+		}
+
+		if i == len(am) {
+			break
+		}
+		// kept element
+		pos++
+		i++
+		k++
+	}
+}
@@ -0,0 +1,149 @@
+package easyjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func mustParsePatch(t *testing.T, s string) Patch {
+	t.Helper()
+	p, err := ParsePatch([]byte(s))
+	if err != nil {
+		t.Fatalf("ParsePatch failed: %v", err)
+	}
+	return p
+}
+
+func TestPatch_ApplyOperations(t *testing.T) {
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`, `{"foo":["bar",["abc"]]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+	}
+	for i, c := range cases {
+		j := mustJSONFromString(t, c.doc)
+		if err := j.ApplyPatch(mustParsePatch(t, c.patch)); err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if got := j.ToString(); got != c.want {
+			t.Fatalf("case %d\nwant: %s\ngot : %s", i, c.want, got)
+		}
+	}
+}
+
+func TestPatch_AtomicFailure(t *testing.T) {
+	j := mustJSONFromString(t, `{"a":1,"arr":[1,2]}`)
+	before := j.ToString()
+	p := mustParsePatch(t, `[
+		{"op":"replace","path":"/a","value":2},
+		{"op":"add","path":"/arr/-","value":3},
+		{"op":"test","path":"/a","value":1}
+	]`)
+	err := j.ApplyPatch(p)
+	var perr *PatchError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *PatchError, got %v", err)
+	}
+	if perr.Index != 2 {
+		t.Fatalf("expected failing index 2, got %d", perr.Index)
+	}
+	if j.ToString() != before {
+		t.Fatalf("document must be untouched after failed patch, got %s", j.ToString())
+	}
+}
+
+func TestPatch_Errors(t *testing.T) {
+	bad := []string{
+		`[{"op":"add","path":"/a/b","value":1}]`,
+		`[{"op":"remove","path":"/missing"}]`,
+		`[{"op":"replace","path":"/missing","value":1}]`,
+		`[{"op":"add","path":"/arr/5","value":1}]`,
+		`[{"op":"move","from":"/obj","path":"/obj/child"}]`,
+		`[{"op":"copy","from":"/missing","path":"/x"}]`,
+		`[{"op":"test","path":"/arr","value":[1]}]`,
+	}
+	for _, s := range bad {
+		j := mustJSONFromString(t, `{"arr":[1,2],"obj":{}}`)
+		if err := j.ApplyPatch(mustParsePatch(t, s)); err == nil {
+			t.Fatalf("expected error for %s", s)
+		}
+	}
+
+	invalid := []string{
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"frobnicate","path":"/a"}]`,
+		`[{"path":"/a"}]`,
+	}
+	for _, s := range invalid {
+		if _, err := ParsePatch([]byte(s)); err == nil {
+			t.Fatalf("expected parse error for %s", s)
+		}
+	}
+}
+
+func TestPatch_ToBytesRoundtrip(t *testing.T) {
+	p := Patch{
+		{Op: PatchOpAdd, Path: "/a", Value: nil},
+		{Op: PatchOpRemove, Path: "/b"},
+		{Op: PatchOpMove, From: "/c", Path: "/d"},
+	}
+	got, err := ParsePatch(p.ToBytes())
+	if err != nil {
+		t.Fatalf("ParsePatch failed: %v", err)
+	}
+	if len(got) != 3 || got[0].Op != PatchOpAdd || got[0].Value != nil || got[2].From != "/c" {
+		t.Fatalf("unexpected roundtrip result: %s", got.ToBytes())
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+	cases := []struct{ from, to string }{
+		{`{"a":1,"b":{"c":2}}`, `{"a":1,"b":{"c":3,"d":4}}`},
+		{`{"a":1,"b":2}`, `{"a":1}`},
+		{`[1,2,3,4,5]`, `[1,3,4,6,5]`},
+		{`[1,2,3]`, `[0,1,2,3,4]`},
+		{`{"items":[{"id":1,"v":"a"},{"id":2,"v":"b"}]}`, `{"items":[{"id":1,"v":"x"},{"id":3,"v":"c"},{"id":2,"v":"b"}]}`},
+		{`{"a":[1,2]}`, `{"a":"str"}`},
+		{`[]`, `{}`},
+		{`{"a/b":{"~":1}}`, `{"a/b":{"~":2}}`},
+	}
+	for _, c := range cases {
+		from := mustJSONFromString(t, c.from)
+		to := mustJSONFromString(t, c.to)
+		p := CreatePatch(from, to)
+		got := from.Clone()
+		if err := got.ApplyPatch(p); err != nil {
+			t.Fatalf("%s -> %s: applying generated patch failed: %v\npatch: %s", c.from, c.to, err, p.ToBytes())
+		}
+		if !got.Equals(to) {
+			t.Fatalf("%s -> %s: got %s\npatch: %s", c.from, c.to, got.ToString(), p.ToBytes())
+		}
+	}
+
+	same := mustJSONFromString(t, `{"a":[1,{"b":2}]}`)
+	if p := CreatePatch(same, same.Clone()); len(p) != 0 {
+		t.Fatalf("expected empty patch for equal documents, got %s", p.ToBytes())
+	}
+
+	p := CreatePatch(mustJSONFromString(t, `{"a":{"b":1,"c":2}}`), mustJSONFromString(t, `{"a":{"b":1,"c":3}}`))
+	if len(p) != 1 || p[0].Op != PatchOpReplace || p[0].Path != "/a/c" {
+		t.Fatalf("expected a single replace of /a/c, got %s", p.ToBytes())
+	}
+}
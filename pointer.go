@@ -0,0 +1,289 @@
+package easyjson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RFC 6901 JSON Pointer support.
+//
+// A pointer is either the empty string (the whole document) or a sequence of
+// "/"-prefixed reference tokens. Inside a token "~1" stands for "/" and "~0"
+// stands for "~", so any object key can be addressed regardless of which
+// characters it contains. The array token "-" refers to the (nonexistent)
+// element after the last one and is only meaningful when setting values.
+
+// EscapePointerToken escapes a single reference token for use in a JSON Pointer.
+func EscapePointerToken(tok string) string {
+	if !strings.ContainsAny(tok, "~/") {
+		return tok
+	}
+	tok = strings.ReplaceAll(tok, "~", "~0")
+	return strings.ReplaceAll(tok, "/", "~1")
+}
+
+// UnescapePointerToken reverses EscapePointerToken.
+// Returns false if the token contains an invalid "~" escape sequence.
+func UnescapePointerToken(tok string) (string, bool) {
+	if !strings.Contains(tok, "~") {
+		return tok, true
+	}
+	var b strings.Builder
+	b.Grow(len(tok))
+	for i := 0; i < len(tok); i++ {
+		c := tok[i]
+		if c != '~' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(tok) {
+			return "", false
+		}
+		switch tok[i+1] {
+		case '0':
+			b.WriteByte('~')
+		case '1':
+			b.WriteByte('/')
+		default:
+			return "", false
+		}
+		i++
+	}
+	return b.String(), true
+}
+
+// BuildPointer composes a JSON Pointer from unescaped reference tokens.
+func BuildPointer(tokens ...string) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		b.WriteByte('/')
+		b.WriteString(EscapePointerToken(tok))
+	}
+	return b.String()
+}
+
+// ParsePointer splits a JSON Pointer into its unescaped reference tokens.
+// Returns false if the pointer is not a valid RFC 6901 pointer.
+func ParsePointer(p string) ([]string, bool) {
+	if p == "" {
+		return []string{}, true
+	}
+	if p[0] != '/' {
+		return nil, false
+	}
+	parts := strings.Split(p[1:], "/")
+	for i, part := range parts {
+		tok, ok := UnescapePointerToken(part)
+		if !ok {
+			return nil, false
+		}
+		parts[i] = tok
+	}
+	return parts, true
+}
+
+// pointerArrayIndex parses an array reference token.
+// Per RFC 6901 only non-negative decimal integers without leading zeros are valid.
+func pointerArrayIndex(tok string) (int, bool) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return 0, false
+		}
+	}
+	idx, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, false
+	}
+	return idx, true
+}
+
+// jvGetByTokens walks already parsed pointer tokens and returns the value found.
+func jvGetByTokens(jv interface{}, tokens []string) (interface{}, bool) {
+	cur := jv
+	for _, tok := range tokens {
+		if obj, ok := jvObject(cur); ok {
+			nv, ok := obj[tok]
+			if !ok {
+				return nil, false
+			}
+			cur = nv
+			continue
+		}
+		switch v := cur.(type) {
+		case []interface{}:
+			idx, ok := pointerArrayIndex(tok)
+			if !ok || idx >= len(v) {
+				return nil, false
+			}
+			cur = v[idx]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// jvSetByTokens sets v at the location described by tokens.
+// Missing intermediate object members are created as objects; array
+// elements are replaced, and "-" or an index equal to the array length
+// appends. When insert is true an array index shifts the following
+// elements to the right instead of replacing the element in place.
+func jvSetByTokens(jv *interface{}, tokens []string, v interface{}, insert bool) bool {
+	if len(tokens) == 0 {
+		*jv = v
+		return true
+	}
+
+	var set func(cur interface{}, pos int) (interface{}, bool)
+	set = func(cur interface{}, pos int) (interface{}, bool) {
+		tok := tokens[pos]
+		last := pos == len(tokens)-1
+
+		if obj, ok := jvObject(cur); ok {
+			if last {
+				jvObjectSet(cur, tok, v)
+				return cur, true
+			}
+			child, exists := obj[tok]
+			if !exists || child == nil {
+				child = jvNewObjectLike(cur)
+			}
+			newChild, ok := set(child, pos+1)
+			if !ok {
+				return cur, false
+			}
+			jvObjectSet(cur, tok, newChild)
+			return cur, true
+		}
+
+		switch cv := cur.(type) {
+		case []interface{}:
+			if last {
+				if tok == "-" {
+					return append(cv, v), true
+				}
+				idx, ok := pointerArrayIndex(tok)
+				if !ok || idx > len(cv) {
+					return cur, false
+				}
+				if idx == len(cv) {
+					return append(cv, v), true
+				}
+				if insert {
+					cv = append(cv, nil)
+					copy(cv[idx+1:], cv[idx:])
+				}
+				cv[idx] = v
+				return cv, true
+			}
+			idx, ok := pointerArrayIndex(tok)
+			if !ok || idx >= len(cv) {
+				return cur, false
+			}
+			newChild, ok := set(cv[idx], pos+1)
+			if !ok {
+				return cur, false
+			}
+			cv[idx] = newChild
+			return cv, true
+
+		default:
+			return cur, false
+		}
+	}
+
+	newRoot, ok := set(*jv, 0)
+	if !ok {
+		return false
+	}
+	*jv = newRoot
+	return true
+}
+
+// jvRemoveByTokens removes the value at tokens. Array elements are spliced
+// out, so the following elements shift to the left. Returns the removed value.
+func jvRemoveByTokens(jv *interface{}, tokens []string) (interface{}, bool) {
+	if len(tokens) == 0 {
+		old := *jv
+		*jv = nil
+		return old, true
+	}
+	parentTokens, tok := tokens[:len(tokens)-1], tokens[len(tokens)-1]
+	parent, ok := jvGetByTokens(*jv, parentTokens)
+	if !ok {
+		return nil, false
+	}
+	if obj, ok := jvObject(parent); ok {
+		old, ok := obj[tok]
+		if !ok {
+			return nil, false
+		}
+		jvObjectDelete(parent, tok)
+		return old, true
+	}
+	switch pv := parent.(type) {
+	case []interface{}:
+		idx, ok := pointerArrayIndex(tok)
+		if !ok || idx >= len(pv) {
+			return nil, false
+		}
+		old := pv[idx]
+		shrunk := append(pv[:idx:idx], pv[idx+1:]...)
+		return old, jvSetByTokens(jv, parentTokens, shrunk, false)
+	default:
+		return nil, false
+	}
+}
+
+// PointerExists checks whether the JSON Pointer p resolves to a value.
+func (j JSON) PointerExists(p string) bool {
+	tokens, ok := ParsePointer(p)
+	if !ok {
+		return false
+	}
+	_, ok = jvGetByTokens(j.Value, tokens)
+	return ok
+}
+
+// GetByPointer returns the value referenced by the JSON Pointer p,
+// or null if p is invalid or does not resolve.
+func (j JSON) GetByPointer(p string) JSON {
+	tokens, ok := ParsePointer(p)
+	if !ok {
+		return NewJSONNull()
+	}
+	v, ok := jvGetByTokens(j.Value, tokens)
+	if !ok {
+		return NewJSONNull()
+	}
+	return NewJSON(v)
+}
+
+// SetByPointer sets a value at the location referenced by the JSON Pointer p.
+// Missing intermediate members are created as objects; the array token "-"
+// appends to the array. Setting an undefined value removes the location
+// like RemoveByPointer, and reports success if it is absent afterwards.
+func (j *JSON) SetByPointer(p string, v JSON) bool {
+	tokens, ok := ParsePointer(p)
+	if !ok {
+		return false
+	}
+	if v.IsUndefined() {
+		return len(tokens) > 0 && (j.RemoveByPointer(p) || !j.PointerExists(p))
+	}
+	return jvSetByTokens(&j.Value, tokens, v.Value, false)
+}
+
+// RemoveByPointer removes the value referenced by the JSON Pointer p.
+// Unlike RemoveByPath, array elements are removed rather than set to null.
+func (j *JSON) RemoveByPointer(p string) bool {
+	tokens, ok := ParsePointer(p)
+	if !ok {
+		return false
+	}
+	_, ok = jvRemoveByTokens(&j.Value, tokens)
+	return ok
+}
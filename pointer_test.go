@@ -0,0 +1,132 @@
+package easyjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPointer_EscapeRoundtrip(t *testing.T) {
+	keys := []string{"a", "a/b", "m~n", "~1", "", "/~/"}
+	for _, k := range keys {
+		esc := EscapePointerToken(k)
+		got, ok := UnescapePointerToken(esc)
+		if !ok || got != k {
+			t.Fatalf("roundtrip %q -> %q -> %q (ok=%v)", k, esc, got, ok)
+		}
+	}
+	if _, ok := UnescapePointerToken("a~2"); ok {
+		t.Fatalf("~2 must be rejected")
+	}
+	if _, ok := UnescapePointerToken("a~"); ok {
+		t.Fatalf("trailing ~ must be rejected")
+	}
+}
+
+func TestPointer_ParseAndBuild(t *testing.T) {
+	tokens, ok := ParsePointer("/a/b~1c/0/~0")
+	if !ok {
+		t.Fatalf("ParsePointer failed")
+	}
+	want := []string{"a", "b/c", "0", "~"}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Fatalf("tokens mismatch: want %v, got %v", want, tokens)
+	}
+	if p := BuildPointer(want...); p != "/a/b~1c/0/~0" {
+		t.Fatalf("BuildPointer mismatch: %s", p)
+	}
+	if _, ok := ParsePointer("a/b"); ok {
+		t.Fatalf("pointer without leading slash must be rejected")
+	}
+}
+
+// Examples from RFC 6901, section 5.
+func TestPointer_RFCExamples(t *testing.T) {
+	j := mustJSONFromString(t, `{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`)
+
+	cases := map[string]float64{
+		"/":     0,
+		"/a~1b": 1,
+		"/c%d":  2,
+		"/e^f":  3,
+		"/g|h":  4,
+		"/i\\j": 5,
+		"/k\"l": 6,
+		"/ ":    7,
+		"/m~0n": 8,
+	}
+	for p, want := range cases {
+		if got := j.GetByPointer(p).AsNumericDefault(-1); got != want {
+			t.Fatalf("%s: want %v, got %v", p, want, got)
+		}
+	}
+	if s := j.GetByPointer("/foo/0").AsStringDefault(""); s != "bar" {
+		t.Fatalf("/foo/0: want bar, got %q", s)
+	}
+	if !j.GetByPointer("").Equals(j) {
+		t.Fatalf("empty pointer must reference the whole document")
+	}
+}
+
+func TestPointer_ExistsAndInvalidIndex(t *testing.T) {
+	j := mustJSONFromString(t, `{"arr":[1,2,3],"n":null}`)
+	if !j.PointerExists("/arr/2") || !j.PointerExists("/n") {
+		t.Fatalf("expected pointers to exist")
+	}
+	for _, p := range []string{"/arr/3", "/arr/-", "/arr/01", "/arr/-1", "/x", "/n/a"} {
+		if j.PointerExists(p) {
+			t.Fatalf("%s should not exist", p)
+		}
+	}
+}
+
+func TestPointer_SetAndAppend(t *testing.T) {
+	j := NewJSONObject()
+	if !j.SetByPointer("/a.b/c~1d", NewJSON("x")) {
+		t.Fatalf("SetByPointer failed")
+	}
+	obj, _ := j.GetByPointer("/a.b").AsObject()
+	if obj["c/d"] != "x" {
+		t.Fatalf("expected key with dot and slash to be set, got %s", j.ToString())
+	}
+
+	j.SetByPointer("/arr", NewJSONArray())
+	j.SetByPointer("/arr/-", NewJSON(1))
+	j.SetByPointer("/arr/1", NewJSON(2))
+	j.SetByPointer("/arr/0", NewJSON(0))
+	if got := j.GetByPointer("/arr").ToString(); got != "[0,2]" {
+		t.Fatalf("unexpected array: %s", got)
+	}
+	if j.SetByPointer("/arr/5", NewJSON(5)) {
+		t.Fatalf("setting past the end of an array must fail")
+	}
+}
+
+func TestPointer_Remove(t *testing.T) {
+	j := mustJSONFromString(t, `{"a":{"b":1,"c":2},"arr":[1,2,3]}`)
+	if !j.RemoveByPointer("/a/b") {
+		t.Fatalf("RemoveByPointer /a/b failed")
+	}
+	if j.PointerExists("/a/b") {
+		t.Fatalf("/a/b should be removed")
+	}
+	if !j.RemoveByPointer("/arr/1") {
+		t.Fatalf("RemoveByPointer /arr/1 failed")
+	}
+	if got := j.GetByPointer("/arr").ToString(); got != "[1,3]" {
+		t.Fatalf("expected element to be spliced out, got %s", got)
+	}
+	if j.RemoveByPointer("/missing") {
+		t.Fatalf("removing a missing member must fail")
+	}
+}
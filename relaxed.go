@@ -0,0 +1,380 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Syntax selects the input dialect accepted by ParseJSON.
+type Syntax int
+
+const (
+	// SyntaxJSON accepts strict RFC 8259 JSON only.
+	SyntaxJSON Syntax = iota
+	// SyntaxJSONC additionally accepts // and /* */ comments and trailing
+	// commas in objects and arrays, as used by editor configuration files.
+	SyntaxJSONC
+	// SyntaxJSON5 accepts JSON5: everything SyntaxJSONC does plus unquoted
+	// identifier keys, single-quoted strings, the escapes \', \v, \0, \xHH
+	// and escaped line breaks, hexadecimal numbers, leading '+' signs,
+	// leading and trailing decimal points, Infinity and NaN, and Unicode
+	// whitespace. Infinity and NaN are decoded as float64 in every NumberMode
+	// and cannot be written back with ToBytes.
+	SyntaxJSON5
+)
+
+// Comment is a comment of JSONC or JSON5 input.
+type Comment struct {
+	// Text is the comment without its delimiters: what follows // up to the
+	// end of the line, or what lies between /* and */.
+	Text string
+	// Block reports a /* */ comment.
+	Block bool
+}
+
+// Comments holds the comments of a document keyed by the JSON Pointer of the
+// value they are attached to, "" being the whole document. ParseJSON adds to
+// it when set as ParseOptions.Comments, and ToBytesWith writes it back out
+// when set as SerializeOptions.Comments.
+type Comments struct {
+	// Before holds the comments preceding a value; for object members they
+	// precede the key.
+	Before map[string][]Comment
+	// After holds the comments following a value on the same line. After[""]
+	// also receives the comments that follow the document.
+	After map[string][]Comment
+	// End holds the comments following the last element of an object or
+	// array, before its closing bracket.
+	End map[string][]Comment
+}
+
+func addComments(m *map[string][]Comment, ptr string, cs ...Comment) {
+	if len(cs) == 0 {
+		return
+	}
+	if *m == nil {
+		*m = map[string][]Comment{}
+	}
+	(*m)[ptr] = append((*m)[ptr], cs...)
+}
+
+// skipRelaxed skips whitespace and comments of the JSONC and JSON5 syntaxes.
+func (p *parser) skipRelaxed() error {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch c {
+		case ' ', '\t':
+			p.pos++
+			continue
+		case '\n', '\r':
+			p.newline = true
+			p.pos++
+			continue
+		case '/':
+			if ok, err := p.comment(); !ok || err != nil {
+				return err
+			}
+			continue
+		}
+		if p.opts.Syntax != SyntaxJSON5 {
+			return nil
+		}
+		r, size := rune(c), 1
+		if c >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(p.data[p.pos:])
+		}
+		switch {
+		case r == '\u2028' || r == '\u2029':
+			p.newline = true
+		case r == '\v' || r == '\f' || r == '\ufeff' || unicode.Is(unicode.Zs, r):
+		default:
+			return nil
+		}
+		p.pos += size
+	}
+	return nil
+}
+
+// comment reads the comment starting at p.pos. It reports false, leaving
+// p.pos on the slash, if the slash does not start a comment.
+func (p *parser) comment() (bool, error) {
+	if p.pos+1 >= len(p.data) {
+		return false, nil
+	}
+	var cm Comment
+	switch p.data[p.pos+1] {
+	case '/':
+		end := p.pos + 2
+		for end < len(p.data) && p.data[end] != '\n' && p.data[end] != '\r' {
+			end++
+		}
+		cm.Text = string(p.data[p.pos+2 : end])
+		p.pos = end
+	case '*':
+		n := bytes.Index(p.data[p.pos+2:], []byte("*/"))
+		if n < 0 {
+			return false, p.errorf("'*/'", "unterminated comment")
+		}
+		cm = Comment{Text: string(p.data[p.pos+2 : p.pos+2+n]), Block: true}
+		p.pos += n + 4
+	default:
+		return false, nil
+	}
+	if p.opts.Comments == nil {
+		return true, nil
+	}
+	if p.hasLast && !p.newline {
+		addComments(&p.opts.Comments.After, p.last, cm)
+	} else {
+		p.pending = append(p.pending, cm)
+	}
+	return true, nil
+}
+
+// beginValue attaches the pending comments to the value about to be parsed.
+func (p *parser) beginValue() {
+	addComments(&p.opts.Comments.Before, BuildPointer(p.path...), p.pending...)
+	p.pending = p.pending[:0]
+	p.hasLast = false
+}
+
+// endValue makes the value just parsed the target of comments that follow
+// it on the same line.
+func (p *parser) endValue() {
+	p.last = BuildPointer(p.path...)
+	p.hasLast = true
+	p.newline = false
+}
+
+// endContainer attaches the pending comments to the end of the object or
+// array just closed.
+func (p *parser) endContainer() {
+	if p.opts.Comments == nil {
+		return
+	}
+	addComments(&p.opts.Comments.End, BuildPointer(p.path...), p.pending...)
+	p.pending = p.pending[:0]
+}
+
+// endDocument attaches the comments after the top-level value to it.
+func (p *parser) endDocument() {
+	if p.opts.Comments == nil {
+		return
+	}
+	addComments(&p.opts.Comments.After, "", p.pending...)
+	p.pending = p.pending[:0]
+}
+
+func (p *parser) enterMember(key string) {
+	if p.opts.Comments != nil {
+		p.path = append(p.path, key)
+	}
+}
+
+func (p *parser) enterElement(i int) {
+	if p.opts.Comments != nil {
+		p.path = append(p.path, strconv.Itoa(i))
+	}
+}
+
+func (p *parser) leave() {
+	if p.opts.Comments != nil {
+		p.path = p.path[:len(p.path)-1]
+	}
+}
+
+// trailingComma reports whether the relaxed syntaxes allow closing the
+// container with close right after a comma.
+func (p *parser) trailingComma(close byte) bool {
+	return p.opts.Syntax != SyntaxJSON && p.pos < len(p.data) && p.data[p.pos] == close
+}
+
+// value5 parses the JSON5 forms of strings and numbers. It reports false if
+// the input at p.pos is none of them.
+func (p *parser) value5() (interface{}, bool, error) {
+	switch c := p.data[p.pos]; {
+	case c == '\'':
+		s, err := p.string()
+		return s, true, err
+	case c == '-' || c == '+' || c == '.' || c == 'I' || c == 'N' || (c >= '0' && c <= '9'):
+		v, err := p.number5()
+		return v, true, err
+	}
+	return nil, false, nil
+}
+
+// number5 parses a JSON5 number and decodes it according to the NumberMode
+// of its JSON equivalent.
+func (p *parser) number5() (interface{}, error) {
+	start := p.pos
+	sign := ""
+	if c := p.data[p.pos]; c == '+' || c == '-' {
+		if c == '-' {
+			sign = "-"
+		}
+		p.pos++
+	}
+	rest := p.data[p.pos:]
+	switch {
+	case strings.HasPrefix(string(rest), "Infinity"):
+		p.pos += len("Infinity")
+		if sign == "-" {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case strings.HasPrefix(string(rest), "NaN"):
+		p.pos += len("NaN")
+		return math.NaN(), nil
+	case len(rest) > 1 && rest[0] == '0' && (rest[1] == 'x' || rest[1] == 'X'):
+		p.pos += 2
+		from := p.pos
+		for p.pos < len(p.data) && isHexDigit(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == from {
+			return nil, p.errorf("hex digit", "unexpected %s in number", p.describe())
+		}
+		n, _ := new(big.Int).SetString(string(p.data[from:p.pos]), 16)
+		return p.decodeNumber(start, sign+n.String())
+	}
+
+	digits := func() string {
+		from := p.pos
+		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			p.pos++
+		}
+		return string(p.data[from:p.pos])
+	}
+	intPart := digits()
+	if len(intPart) > 1 && intPart[0] == '0' {
+		p.pos -= len(intPart) - 1
+		return nil, p.errorf("'.' or 'e'", "unexpected %s after leading zero", p.describe())
+	}
+	var frac string
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		frac = digits()
+	}
+	if intPart == "" && frac == "" {
+		return nil, p.errorf("digit", "unexpected %s in number", p.describe())
+	}
+	text := sign
+	if intPart == "" {
+		intPart = "0"
+	}
+	text += intPart
+	if frac != "" {
+		text += "." + frac
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		exp := "e"
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			exp += string(p.data[p.pos])
+			p.pos++
+		}
+		d := digits()
+		if d == "" {
+			return nil, p.errorf("digit", "unexpected %s in exponent", p.describe())
+		}
+		text += exp + d
+	}
+	return p.decodeNumber(start, text)
+}
+
+// decodeNumber decodes the JSON number text of the JSON5 number at start.
+func (p *parser) decodeNumber(start int, text string) (interface{}, error) {
+	v, err := parseNumber(json.Number(text), p.opts.Numbers)
+	if err != nil {
+		lit := p.data[start:p.pos]
+		p.pos = start
+		return nil, p.errorf("", "number %s out of range", lit)
+	}
+	return v, nil
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// escape5 decodes the JSON5 escape sequences that JSON lacks. p.pos is on
+// the character after the backslash and is left after the sequence.
+func (p *parser) escape5(sb *strings.Builder) error {
+	c := p.data[p.pos]
+	switch {
+	case c == '\'':
+		sb.WriteByte(c)
+	case c == 'v':
+		sb.WriteByte('\v')
+	case c == '0':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] >= '0' && p.data[p.pos+1] <= '9' {
+			return p.errorf("escape sequence", "invalid escape %q", "\\0"+string(rune(p.data[p.pos+1])))
+		}
+		sb.WriteByte(0)
+	case c == 'x':
+		r, err := p.hex(2)
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(r)
+	case c >= '1' && c <= '9':
+		return p.errorf("escape sequence", "invalid escape %q", "\\"+string(rune(c)))
+	case c == '\r':
+		// An escaped line break continues the string on the next line.
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n' {
+			p.pos++
+		}
+	case c == '\n':
+	default:
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if r != '\u2028' && r != '\u2029' {
+			sb.WriteRune(r)
+		}
+		p.pos += size
+		return nil
+	}
+	p.pos++
+	return nil
+}
+
+// key5 reads a JSON5 object key: a quoted string or an identifier.
+func (p *parser) key5() (string, error) {
+	if p.pos < len(p.data) {
+		if c := p.data[p.pos]; c == '"' || c == '\'' {
+			return p.string()
+		}
+	}
+	start := p.pos
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if !isIdentRune(r, p.pos == start) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return "", p.errorf("object key", "unexpected %s", p.describe())
+	}
+	if p.opts.MaxStringLength > 0 && p.pos-start > p.opts.MaxStringLength {
+		return "", p.limitError(start, ErrLimitExceeded, "string longer than %d bytes", p.opts.MaxStringLength)
+	}
+	return string(p.data[start:p.pos]), nil
+}
+
+// isIdentRune reports whether r may appear in an ECMAScript identifier name.
+func isIdentRune(r rune, first bool) bool {
+	switch {
+	case r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r):
+		return true
+	case first:
+		return false
+	}
+	return r == '\u200c' || r == '\u200d' ||
+		unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
@@ -0,0 +1,203 @@
+package easyjson
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSON_JSONC(t *testing.T) {
+	src := `// service config
+{
+	"name": "svc", /* inline */
+	"ports": [80, 443,], // trailing comma
+	/* block
+	   comment */ "debug": false,
+}
+`
+	j, err := ParseJSONString(src, ParseOptions{Syntax: SyntaxJSONC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name":  "svc",
+		"ports": []interface{}{float64(80), float64(443)},
+		"debug": false,
+	}
+	if !reflect.DeepEqual(j.Value, want) {
+		t.Fatalf("got %#v", j.Value)
+	}
+
+	for _, in := range []string{`{"a":1} // x`, `[1,]`, `{"a":1,}`} {
+		if _, err := ParseJSONString(in); err == nil {
+			t.Errorf("%q: strict JSON must be rejected", in)
+		}
+	}
+	for _, in := range []string{`[1,,]`, `[,]`, `{,}`, `{a:1}`, `'x'`, `/* open`, `[1 / 2]`} {
+		if _, err := ParseJSONString(in, ParseOptions{Syntax: SyntaxJSONC}); err == nil {
+			t.Errorf("%q: expected a JSONC error", in)
+		}
+	}
+
+	_, err = ParseJSONString("[1,\n /* open", ParseOptions{Syntax: SyntaxJSONC})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 || perr.Column != 2 || perr.Msg != "unterminated comment" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseJSON_JSON5(t *testing.T) {
+	src := `{
+  // JSON5 example
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here',
+  lineBreaks: "Look, Mom! \
+No \\n's!",
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  trailingComma: 'in objects', andIn: ['arrays',],
+  "backwardsCompatible": "with JSON",
+  $id_1: '\x41B\'\0',
+}`
+	j, err := ParseJSONString(src, ParseOptions{Syntax: SyntaxJSON5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"unquoted":            "and you can quote me on that",
+		"singleQuotes":        `I can use "double quotes" here`,
+		"lineBreaks":          `Look, Mom! No \n's!`,
+		"hexadecimal":         float64(0xdecaf),
+		"leadingDecimalPoint": 0.8675309,
+		"andTrailing":         8675309.0,
+		"positiveSign":        float64(1),
+		"trailingComma":       "in objects",
+		"andIn":               []interface{}{"arrays"},
+		"backwardsCompatible": "with JSON",
+		"$id_1":               "AB'\x00",
+	}
+	if !reflect.DeepEqual(j.Value, want) {
+		t.Fatalf("got %#v", j.Value)
+	}
+
+	j, err = ParseJSONString("[Infinity, -Infinity, NaN, -0x10, 0xFFFFFFFFFFFFFFFFFF]",
+		ParseOptions{Syntax: SyntaxJSON5, Numbers: NumberTyped})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := j.Value.([]interface{})
+	if !math.IsInf(arr[0].(float64), 1) || !math.IsInf(arr[1].(float64), -1) || !math.IsNaN(arr[2].(float64)) {
+		t.Fatalf("unexpected special numbers %#v", arr[:3])
+	}
+	if arr[3] != int64(-16) {
+		t.Fatalf("negative hex: %#v", arr[3])
+	}
+	if s, _ := NewJSON(arr[4]).AsDecimalString(); s != "4722366482869645213695" {
+		t.Fatalf("big hex: %v", s)
+	}
+
+	for _, in := range []string{`01`, `0x`, `.`, `+`, `'\1'`, `'\01'`, `{1a: 0}`, `"a b`, `{a b: 1}`} {
+		if _, err := ParseJSONString(in, ParseOptions{Syntax: SyntaxJSON5}); err == nil {
+			t.Errorf("%q: expected a JSON5 error", in)
+		}
+	}
+	if _, err := ParseJSONString(`{a: 1}`, ParseOptions{Syntax: SyntaxJSONC}); err == nil {
+		t.Error("JSONC must not accept unquoted keys")
+	}
+}
+
+func TestParseJSON_Comments(t *testing.T) {
+	src := `// header
+{
+  // the service name
+  "name": "svc", // inline
+  "ports": [
+    80, /* http */
+    // https
+    443
+    // more to come
+  ],
+  "empty": {
+    // nothing yet
+  }
+} // trailer
+// eof`
+	var cs Comments
+	j, err := ParseJSONString(src, ParseOptions{Syntax: SyntaxJSONC, PreserveKeyOrder: true, Comments: &cs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := func(texts ...string) []Comment {
+		var out []Comment
+		for _, s := range texts {
+			out = append(out, Comment{Text: s})
+		}
+		return out
+	}
+	wantBefore := map[string][]Comment{
+		"":         line(" header"),
+		"/name":    line(" the service name"),
+		"/ports/1": line(" https"),
+	}
+	wantAfter := map[string][]Comment{
+		"/name":    line(" inline"),
+		"/ports/0": {{Text: " http ", Block: true}},
+		"":         line(" trailer", " eof"),
+	}
+	wantEnd := map[string][]Comment{
+		"/ports": line(" more to come"),
+		"/empty": line(" nothing yet"),
+	}
+	if !reflect.DeepEqual(cs.Before, wantBefore) {
+		t.Errorf("Before = %#v", cs.Before)
+	}
+	if !reflect.DeepEqual(cs.After, wantAfter) {
+		t.Errorf("After = %#v", cs.After)
+	}
+	if !reflect.DeepEqual(cs.End, wantEnd) {
+		t.Errorf("End = %#v", cs.End)
+	}
+
+	out, err := j.ToBytesWith(SerializeOptions{Indent: "  ", Comments: &cs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `// header
+{
+  // the service name
+  "name": "svc", // inline
+  "ports": [
+    80, /* http */
+    // https
+    443
+    // more to come
+  ],
+  "empty": {
+    // nothing yet
+  }
+} // trailer
+// eof`
+	if string(out) != want {
+		t.Fatalf("indented output:\n%s", out)
+	}
+
+	out, err = j.ToBytesWith(SerializeOptions{Comments: &cs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `/* header*/{/* the service name*/"name":"svc",/* inline*/"ports":[80,/* http *//* https*/443/* more to come*/],"empty":{/* nothing yet*/}}/* trailer*//* eof*/`
+	if string(out) != want {
+		t.Fatalf("compact output:\n%s", out)
+	}
+	back, err := ParseJSON(out, ParseOptions{Syntax: SyntaxJSONC})
+	if err != nil || !back.Equals(j) {
+		t.Fatalf("compact output does not parse back: %v", err)
+	}
+
+	bad := &Comments{Before: map[string][]Comment{"": {{Text: "a */ b", Block: true}}}}
+	if _, err := j.ToBytesWith(SerializeOptions{Comments: bad}); err == nil {
+		t.Fatal("expected an error for an unwritable comment")
+	}
+}
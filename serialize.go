@@ -0,0 +1,367 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// FloatFormat selects how floating point numbers are written by ToBytesWith.
+type FloatFormat int
+
+const (
+	// FloatShortest writes the shortest representation that round-trips,
+	// switching to exponent notation for very large and very small values
+	// exactly like encoding/json.
+	FloatShortest FloatFormat = iota
+	// FloatFixed writes SerializeOptions.FloatPrecision digits after the
+	// decimal point.
+	FloatFixed
+	// FloatNoExponent writes the shortest representation that round-trips
+	// but never uses exponent notation.
+	FloatNoExponent
+)
+
+// SerializeOptions controls the output of ToBytesWith.
+// The zero value produces the same bytes as ToBytes.
+type SerializeOptions struct {
+	// Indent, when not empty, pretty-prints the output with one Indent per
+	// nesting level. Prefix starts every line after the first.
+	Indent string
+	Prefix string
+	// DisableHTMLEscape writes <, > and & literally instead of as \u003c etc.
+	DisableHTMLEscape bool
+	// SortKeys sorts the members of ordered objects by key as well. Members
+	// of plain Go maps have no order of their own and are always sorted.
+	SortKeys bool
+	// FloatFormat and FloatPrecision control how float32 and float64 values
+	// are written. Parsed numbers are float64, so this applies to all of them.
+	// Integer Go types are always written as integers.
+	FloatFormat    FloatFormat
+	FloatPrecision int
+	// TrailingNewline appends a newline after the document.
+	TrailingNewline bool
+	// Comments, when not nil, writes the comments attached to the values of
+	// the document, as collected by ParseOptions.Comments. The output is then
+	// JSONC rather than JSON. Without Indent every comment is written as a
+	// /* */ block comment.
+	Comments *Comments
+}
+
+// ToBytesWith serializes the JSON value according to opts.
+func (j JSON) ToBytesWith(opts SerializeOptions) ([]byte, error) {
+	if opts.FloatFormat == FloatFixed && opts.FloatPrecision < 0 {
+		return nil, errors.New("easyjson: negative FloatPrecision")
+	}
+	e := &serializer{opts: opts}
+	if err := e.before(0); err != nil {
+		return nil, err
+	}
+	if err := e.value(j.Value, 0); err != nil {
+		return nil, err
+	}
+	if err := e.after(0); err != nil {
+		return nil, err
+	}
+	if opts.TrailingNewline {
+		e.buf.WriteByte('\n')
+	}
+	return e.buf.Bytes(), nil
+}
+
+type serializer struct {
+	opts    SerializeOptions
+	buf     bytes.Buffer
+	scratch bytes.Buffer
+	strEnc  *json.Encoder
+	path    []string // tokens of the value being written, tracked for Comments
+}
+
+func (e *serializer) value(v interface{}, depth int) error {
+	switch x := v.(type) {
+	case nil:
+		e.buf.WriteString("null")
+	case bool:
+		e.buf.WriteString(strconv.FormatBool(x))
+	case string:
+		return e.string(x)
+	case float64:
+		return e.float(x, 64)
+	case float32:
+		return e.float(float64(x), 32)
+	case int:
+		e.buf.WriteString(strconv.FormatInt(int64(x), 10))
+	case int8:
+		e.buf.WriteString(strconv.FormatInt(int64(x), 10))
+	case int16:
+		e.buf.WriteString(strconv.FormatInt(int64(x), 10))
+	case int32:
+		e.buf.WriteString(strconv.FormatInt(int64(x), 10))
+	case int64:
+		e.buf.WriteString(strconv.FormatInt(x, 10))
+	case uint:
+		e.buf.WriteString(strconv.FormatUint(uint64(x), 10))
+	case uint8:
+		e.buf.WriteString(strconv.FormatUint(uint64(x), 10))
+	case uint16:
+		e.buf.WriteString(strconv.FormatUint(uint64(x), 10))
+	case uint32:
+		e.buf.WriteString(strconv.FormatUint(uint64(x), 10))
+	case uint64:
+		e.buf.WriteString(strconv.FormatUint(x, 10))
+	case json.Number:
+		return e.number(x)
+	case *big.Int:
+		if x == nil {
+			e.buf.WriteString("null")
+			break
+		}
+		e.buf.WriteString(x.String())
+	case []interface{}:
+		return e.array(x, depth)
+	case map[string]interface{}, *OrderedObject:
+		return e.object(x, depth)
+	default:
+		return e.foreign(v, depth)
+	}
+	return nil
+}
+
+// foreign serializes values of other Go types by letting encoding/json
+// render them and re-encoding the result with the configured options.
+func (e *serializer) foreign(v interface{}, depth int) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return err
+	}
+	return e.value(x, depth)
+}
+
+func (e *serializer) string(s string) error {
+	if e.strEnc == nil {
+		e.strEnc = json.NewEncoder(&e.scratch)
+		e.strEnc.SetEscapeHTML(!e.opts.DisableHTMLEscape)
+	}
+	e.scratch.Reset()
+	if err := e.strEnc.Encode(s); err != nil {
+		return err
+	}
+	e.buf.Write(bytes.TrimSuffix(e.scratch.Bytes(), []byte{'\n'}))
+	return nil
+}
+
+func (e *serializer) float(f float64, bits int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("easyjson: unsupported float value %v", f)
+	}
+	var b []byte
+	switch e.opts.FloatFormat {
+	case FloatFixed:
+		b = strconv.AppendFloat(nil, f, 'f', e.opts.FloatPrecision, bits)
+	case FloatNoExponent:
+		b = strconv.AppendFloat(nil, f, 'f', -1, bits)
+	default:
+		b = appendShortestFloat(nil, f, bits)
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+// appendShortestFloat formats f the way encoding/json does.
+func appendShortestFloat(b []byte, f float64, bits int) []byte {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+// number writes a json.Number. Integer literals are kept verbatim; other
+// literals are reformatted only when a non-default FloatFormat is requested.
+func (e *serializer) number(n json.Number) error {
+	s := n.String()
+	if e.opts.FloatFormat == FloatShortest || !strings.ContainsAny(s, ".eE") {
+		e.buf.WriteString(s)
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	return e.float(f, 64)
+}
+
+func (e *serializer) pretty() bool {
+	return e.opts.Indent != "" || e.opts.Prefix != ""
+}
+
+func (e *serializer) newline(depth int) {
+	if !e.pretty() {
+		return
+	}
+	e.buf.WriteByte('\n')
+	e.buf.WriteString(e.opts.Prefix)
+	for i := 0; i < depth; i++ {
+		e.buf.WriteString(e.opts.Indent)
+	}
+}
+
+func (e *serializer) array(arr []interface{}, depth int) error {
+	e.buf.WriteByte('[')
+	for i, v := range arr {
+		e.newline(depth + 1)
+		if e.opts.Comments != nil {
+			e.path = append(e.path, strconv.Itoa(i))
+			if err := e.before(depth + 1); err != nil {
+				return err
+			}
+		}
+		if err := e.value(v, depth+1); err != nil {
+			return err
+		}
+		if i < len(arr)-1 {
+			e.buf.WriteByte(',')
+		}
+		if e.opts.Comments != nil {
+			if err := e.after(depth + 1); err != nil {
+				return err
+			}
+			e.path = e.path[:len(e.path)-1]
+		}
+	}
+	return e.close(']', len(arr), depth)
+}
+
+func (e *serializer) object(v interface{}, depth int) error {
+	obj, _ := jvObject(v)
+	keys := jvObjectKeys(v)
+	if e.opts.SortKeys {
+		keys = sortedKeys(obj)
+	}
+	e.buf.WriteByte('{')
+	for i, k := range keys {
+		e.newline(depth + 1)
+		if e.opts.Comments != nil {
+			e.path = append(e.path, k)
+			if err := e.before(depth + 1); err != nil {
+				return err
+			}
+		}
+		if err := e.string(k); err != nil {
+			return err
+		}
+		e.buf.WriteByte(':')
+		if e.pretty() {
+			e.buf.WriteByte(' ')
+		}
+		if err := e.value(obj[k], depth+1); err != nil {
+			return err
+		}
+		if i < len(keys)-1 {
+			e.buf.WriteByte(',')
+		}
+		if e.opts.Comments != nil {
+			if err := e.after(depth + 1); err != nil {
+				return err
+			}
+			e.path = e.path[:len(e.path)-1]
+		}
+	}
+	return e.close('}', len(keys), depth)
+}
+
+// close writes the End comments of the current container and its closing
+// bracket.
+func (e *serializer) close(bracket byte, n int, depth int) error {
+	if e.opts.Comments != nil {
+		for _, c := range e.opts.Comments.End[BuildPointer(e.path...)] {
+			e.newline(depth + 1)
+			if err := e.comment(c); err != nil {
+				return err
+			}
+			n++
+		}
+	}
+	if n > 0 {
+		e.newline(depth)
+	}
+	e.buf.WriteByte(bracket)
+	return nil
+}
+
+// before writes the Before comments of the current value, each on its own
+// line when indenting.
+func (e *serializer) before(depth int) error {
+	if e.opts.Comments == nil {
+		return nil
+	}
+	for _, c := range e.opts.Comments.Before[BuildPointer(e.path...)] {
+		if err := e.comment(c); err != nil {
+			return err
+		}
+		e.newline(depth)
+	}
+	return nil
+}
+
+// after writes the After comments of the current value on its line. A line
+// comment ends the line, so a comment following it starts a new one.
+func (e *serializer) after(depth int) error {
+	if e.opts.Comments == nil {
+		return nil
+	}
+	lineComment := false
+	for _, c := range e.opts.Comments.After[BuildPointer(e.path...)] {
+		if lineComment {
+			e.newline(depth)
+		} else if e.pretty() {
+			e.buf.WriteByte(' ')
+		}
+		if err := e.comment(c); err != nil {
+			return err
+		}
+		lineComment = !c.Block && e.pretty()
+	}
+	return nil
+}
+
+// comment writes c, as a block comment unless it is a line comment and the
+// output is indented.
+func (e *serializer) comment(c Comment) error {
+	if !c.Block && e.pretty() && !strings.ContainsAny(c.Text, "\r\n") {
+		e.buf.WriteString("//")
+		e.buf.WriteString(c.Text)
+		return nil
+	}
+	if strings.Contains(c.Text, "*/") {
+		return fmt.Errorf("easyjson: comment %q cannot be written as a block comment", c.Text)
+	}
+	e.buf.WriteString("/*")
+	e.buf.WriteString(c.Text)
+	e.buf.WriteString("*/")
+	return nil
+}
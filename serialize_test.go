@@ -0,0 +1,73 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestToBytesWith_DefaultMatchesToBytes(t *testing.T) {
+	j := mustJSONFromString(t, `{"b":[1,2.5,1e21,1e-7,-0.000001],"a":"<tag> &  ","c":{"z":null,"y":true},"e":{},"f":[]}`)
+	j.SetByPath("n", NewJSON(int64(-42)))
+	j.SetByPath("s", NewJSON([]float32{0.1}))
+	got, err := j.ToBytesWith(SerializeOptions{})
+	if err != nil {
+		t.Fatalf("ToBytesWith failed: %v", err)
+	}
+	if string(got) != j.ToString() {
+		t.Fatalf("zero options must match ToBytes:\n%s\n%s", got, j.ToString())
+	}
+}
+
+func TestToBytesWith_Indent(t *testing.T) {
+	j := mustJSONFromString(t, `{"b":[1,{}],"a":"x","c":[]}`)
+	got, err := j.ToBytesWith(SerializeOptions{Indent: "  ", TrailingNewline: true})
+	if err != nil {
+		t.Fatalf("ToBytesWith failed: %v", err)
+	}
+	want, _ := json.MarshalIndent(j.Value, "", "  ")
+	if string(got) != string(want)+"\n" {
+		t.Fatalf("unexpected indented output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestToBytesWith_HTMLEscape(t *testing.T) {
+	j := NewJSON(map[string]interface{}{"<k>": "a&b"})
+	got, _ := j.ToBytesWith(SerializeOptions{DisableHTMLEscape: true})
+	if string(got) != `{"<k>":"a&b"}` {
+		t.Fatalf("unexpected output %s", got)
+	}
+}
+
+func TestToBytesWith_Floats(t *testing.T) {
+	j := NewJSON([]interface{}{1.0, 2.5, 1e21, 1e-7, int64(3), json.Number("7"), json.Number("0.125")})
+
+	got, _ := j.ToBytesWith(SerializeOptions{FloatFormat: FloatFixed, FloatPrecision: 2})
+	if string(got) != `[1.00,2.50,1000000000000000000000.00,0.00,3,7,0.12]` {
+		t.Fatalf("unexpected fixed output %s", got)
+	}
+	got, _ = j.ToBytesWith(SerializeOptions{FloatFormat: FloatNoExponent})
+	if string(got) != `[1,2.5,1000000000000000000000,0.0000001,3,7,0.125]` {
+		t.Fatalf("unexpected no-exponent output %s", got)
+	}
+
+	if _, err := NewJSON(math.Inf(1)).ToBytesWith(SerializeOptions{}); err == nil {
+		t.Fatalf("infinity must not serialize")
+	}
+	if _, err := j.ToBytesWith(SerializeOptions{FloatFormat: FloatFixed, FloatPrecision: -1}); err == nil {
+		t.Fatalf("negative precision must fail")
+	}
+}
+
+func TestToBytesWith_ForeignValues(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+	j := NewJSONObject()
+	j.SetByPath("items", NewJSON([]item{{Name: "<a>", Price: 1.5}}))
+	got, err := j.ToBytesWith(SerializeOptions{DisableHTMLEscape: true, FloatFormat: FloatFixed, FloatPrecision: 1})
+	if err != nil || string(got) != `{"items":[{"name":"<a>","price":1.5}]}` {
+		t.Fatalf("unexpected output %s (%v)", got, err)
+	}
+}
@@ -0,0 +1,282 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TokenKind identifies the kind of a streamed Token.
+type TokenKind int
+
+const (
+	TokenBeginObject TokenKind = iota
+	TokenEndObject
+	TokenBeginArray
+	TokenEndArray
+	TokenKey
+	TokenString
+	TokenNumber
+	TokenBool
+	TokenNull
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenBeginObject:
+		return "begin-object"
+	case TokenEndObject:
+		return "end-object"
+	case TokenBeginArray:
+		return "begin-array"
+	case TokenEndArray:
+		return "end-array"
+	case TokenKey:
+		return "key"
+	case TokenString:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenBool:
+		return "bool"
+	case TokenNull:
+		return "null"
+	}
+	return "unknown"
+}
+
+// Token is a single lexical element of a streamed document.
+// Value holds the key or scalar value (nil for delimiters and null).
+// Path is the dot path of the value the token belongs to; for keys it is
+// the path of the member the key introduces.
+type Token struct {
+	Kind  TokenKind
+	Value interface{}
+	Path  string
+}
+
+// Decoder reads a JSON document from an io.Reader token by token, so that
+// arbitrarily large documents can be processed with bounded memory.
+// Subtrees can be materialized as JSON values on demand with Decode or Each.
+type Decoder struct {
+	dec   *json.Decoder
+	stack []streamFrame
+	delim string
+	opts  ParseOptions
+}
+
+type streamFrame struct {
+	array   bool
+	path    []string
+	index   int
+	key     string
+	haveKey bool
+}
+
+// NewDecoder creates a streaming decoder reading from r.
+// The optional delimiter is used to build token paths and to split Each
+// patterns; it defaults to ".".
+func NewDecoder(r io.Reader, delimiter ...string) *Decoder {
+	return NewDecoderWithOptions(r, ParseOptions{}, delimiter...)
+}
+
+// NewDecoderWithOptions is like NewDecoder but builds the values returned by
+// Decode and Each like ParseJSON with opts, and number tokens according to
+// opts.Numbers. The input must be strict JSON whatever opts.Syntax says.
+func NewDecoderWithOptions(r io.Reader, opts ParseOptions, delimiter ...string) *Decoder {
+	d := &Decoder{dec: json.NewDecoder(r), delim: ".", opts: opts}
+	if len(delimiter) > 0 && delimiter[0] != "" {
+		d.delim = delimiter[0]
+	}
+	if opts.Numbers != NumberFloat64 {
+		d.dec.UseNumber()
+	}
+	return d
+}
+
+// Depth returns the number of currently open objects and arrays.
+func (d *Decoder) Depth() int {
+	return len(d.stack)
+}
+
+// More reports whether there is another element in the current array or
+// object, or another top-level value in the stream.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// valuePath returns the path segments of the value that would be read next.
+func (d *Decoder) valuePath() []string {
+	if len(d.stack) == 0 {
+		return nil
+	}
+	f := &d.stack[len(d.stack)-1]
+	seg := f.key
+	if f.array {
+		seg = strconv.Itoa(f.index)
+	}
+	return append(f.path[:len(f.path):len(f.path)], seg)
+}
+
+// valueExpected reports whether the next token starts a value.
+func (d *Decoder) valueExpected() bool {
+	if len(d.stack) == 0 {
+		return true
+	}
+	f := &d.stack[len(d.stack)-1]
+	if f.array {
+		return d.dec.More()
+	}
+	return f.haveKey
+}
+
+// valueDone advances the enclosing container past a completed value.
+func (d *Decoder) valueDone() {
+	if len(d.stack) == 0 {
+		return
+	}
+	f := &d.stack[len(d.stack)-1]
+	if f.array {
+		f.index++
+	} else {
+		f.haveKey = false
+	}
+}
+
+// Token returns the next token of the stream, or io.EOF at the end of input.
+func (d *Decoder) Token() (Token, error) {
+	var path []string
+	expectValue := d.valueExpected()
+	if expectValue {
+		path = d.valuePath()
+	}
+
+	t, err := d.dec.Token()
+	if err != nil {
+		return Token{}, err
+	}
+
+	switch v := t.(type) {
+	case json.Delim:
+		switch v {
+		case '{', '[':
+			d.stack = append(d.stack, streamFrame{array: v == '[', path: path})
+			kind := TokenBeginObject
+			if v == '[' {
+				kind = TokenBeginArray
+			}
+			return Token{Kind: kind, Path: d.joinPath(path)}, nil
+		default:
+			f := d.stack[len(d.stack)-1]
+			d.stack = d.stack[:len(d.stack)-1]
+			d.valueDone()
+			kind := TokenEndObject
+			if v == ']' {
+				kind = TokenEndArray
+			}
+			return Token{Kind: kind, Path: d.joinPath(f.path)}, nil
+		}
+	case string:
+		if !expectValue {
+			f := &d.stack[len(d.stack)-1]
+			f.key, f.haveKey = v, true
+			return Token{Kind: TokenKey, Value: v, Path: d.joinPath(d.valuePath())}, nil
+		}
+		d.valueDone()
+		return Token{Kind: TokenString, Value: v, Path: d.joinPath(path)}, nil
+	case bool:
+		d.valueDone()
+		return Token{Kind: TokenBool, Value: v, Path: d.joinPath(path)}, nil
+	case nil:
+		d.valueDone()
+		return Token{Kind: TokenNull, Path: d.joinPath(path)}, nil
+	case json.Number:
+		n, err := parseNumber(v, d.opts.Numbers)
+		if err != nil {
+			return Token{}, err
+		}
+		d.valueDone()
+		return Token{Kind: TokenNumber, Value: n, Path: d.joinPath(path)}, nil
+	default:
+		d.valueDone()
+		return Token{Kind: TokenNumber, Value: v, Path: d.joinPath(path)}, nil
+	}
+}
+
+// Decode materializes the next complete value of the stream. It must be
+// called where a value is expected: at the top level, after a TokenKey or
+// inside an array. Inside an array io.EOF is returned once there are no more
+// elements; the closing bracket is then read by Token.
+func (d *Decoder) Decode() (JSON, error) {
+	if !d.valueExpected() {
+		if len(d.stack) > 0 && d.stack[len(d.stack)-1].array {
+			return NewJSONNull(), io.EOF
+		}
+		return NewJSONNull(), fmt.Errorf("easyjson: Decode called where no value is expected")
+	}
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return NewJSONNull(), err
+	}
+	j, err := ParseJSON(raw, d.opts)
+	if err != nil {
+		return NewJSONNull(), err
+	}
+	d.valueDone()
+	return j, nil
+}
+
+// Each streams through the rest of the input and calls fn for every value
+// whose path matches pattern. Pattern segments are separated by the
+// decoder's delimiter and "*" matches any single segment, so "items.*"
+// visits the elements of the top-level "items" array one by one. Only the
+// matched values are materialized; everything else is skipped token by token.
+// Returning an error from fn stops the walk and returns that error.
+func (d *Decoder) Each(pattern string, fn func(path string, v JSON) error) error {
+	var pat []string
+	if pattern != "" {
+		pat = strings.Split(pattern, d.delim)
+	}
+	for {
+		if d.valueExpected() {
+			path := d.valuePath()
+			if streamPathMatch(pat, path) {
+				v, err := d.Decode()
+				if err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+				if err := fn(d.joinPath(path), v); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if _, err := d.Token(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (d *Decoder) joinPath(path []string) string {
+	return strings.Join(path, d.delim)
+}
+
+func streamPathMatch(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != path[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package easyjson
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_Tokens(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"a":[1,"x",{"b":null}],"c":true}`))
+	var got []string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		got = append(got, tok.Kind.String()+"@"+tok.Path)
+	}
+	want := []string{
+		"begin-object@",
+		"key@a",
+		"begin-array@a",
+		"number@a.0",
+		"string@a.1",
+		"begin-object@a.2",
+		"key@a.2.b",
+		"null@a.2.b",
+		"end-object@a.2",
+		"end-array@a",
+		"key@c",
+		"bool@c",
+		"end-object@",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("\nwant: %v\ngot : %v", want, got)
+	}
+}
+
+func TestDecoder_EachArrayElement(t *testing.T) {
+	src := `{"meta":{"count":3},"items":[{"id":1,"tags":["a"]},{"id":2},{"id":3}],"tail":"x"}`
+	d := NewDecoder(strings.NewReader(src))
+
+	var ids []float64
+	var paths []string
+	err := d.Each("items.*", func(path string, v JSON) error {
+		paths = append(paths, path)
+		ids = append(ids, v.GetByPath("id").AsNumericDefault(-1))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each failed: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+	if paths[1] != "items.1" {
+		t.Fatalf("unexpected path: %v", paths)
+	}
+}
+
+func TestDecoder_EachNestedAndStop(t *testing.T) {
+	src := `[{"v":{"n":1}},{"v":{"n":2}},{"v":{"n":3}}]`
+	d := NewDecoder(strings.NewReader(src))
+	stop := errors.New("stop")
+	var seen []float64
+	err := d.Each("*.v", func(path string, v JSON) error {
+		seen = append(seen, v.GetByPath("n").AsNumericDefault(0))
+		if len(seen) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || len(seen) != 2 {
+		t.Fatalf("expected to stop after two values, got %v %v", seen, err)
+	}
+}
+
+func TestDecoder_DecodeAfterTokens(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"skip":[1,2,3],"rows":[{"a":1},{"a":2}]}`))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		if tok.Kind == TokenBeginArray && tok.Path == "rows" {
+			break
+		}
+	}
+	var n int
+	for {
+		row, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		n++
+		if row.GetByPath("a").AsNumericDefault(0) != float64(n) {
+			t.Fatalf("unexpected row %d: %s", n, row.ToString())
+		}
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 rows, got %d", n)
+	}
+	if tok, err := d.Token(); err != nil || tok.Kind != TokenEndArray {
+		t.Fatalf("expected end of array, got %v %v", tok, err)
+	}
+}
+
+func TestDecoder_TopLevelStreamAndErrors(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"a":1} {"a":2}`))
+	var n int
+	if err := d.Each("", func(path string, v JSON) error { n++; return nil }); err != nil || n != 2 {
+		t.Fatalf("expected two top-level values, got %d (%v)", n, err)
+	}
+
+	d = NewDecoder(strings.NewReader(`{"a":[1,}`))
+	if err := d.Each("a.*", func(string, JSON) error { return nil }); err == nil {
+		t.Fatalf("expected syntax error")
+	}
+}
+
+func TestDecoder_WithOptions(t *testing.T) {
+	src := `{"rows":[{"z":1,"a":9007199254740993}],"n":12345678901234567890}`
+	d := NewDecoderWithOptions(strings.NewReader(src), ParseOptions{PreserveKeyOrder: true, Numbers: NumberTyped})
+	var rows []JSON
+	if err := d.Each("rows.*", func(path string, v JSON) error { rows = append(rows, v); return nil }); err != nil {
+		t.Fatalf("Each failed: %v", err)
+	}
+	if len(rows) != 1 || !reflect.DeepEqual(rows[0].ObjectKeys(), []string{"z", "a"}) {
+		t.Fatalf("key order must be preserved, got %v", rows)
+	}
+	if n, ok := rows[0].GetByPath("a").Value.(int64); !ok || n != 9007199254740993 {
+		t.Fatalf("numbers must follow NumberTyped, got %T %v", rows[0].GetByPath("a").Value, rows[0].GetByPath("a").Value)
+	}
+
+	d = NewDecoderWithOptions(strings.NewReader(`[12345678901234567890]`), ParseOptions{Numbers: NumberJSONNumber})
+	d.Token()
+	if tok, err := d.Token(); err != nil || tok.Value != json.Number("12345678901234567890") {
+		t.Fatalf("number tokens must follow the number mode, got %#v %v", tok.Value, err)
+	}
+
+	d = NewDecoderWithOptions(strings.NewReader(`[{"a":[[1]]}]`), ParseOptions{MaxDepth: 2})
+	d.Token()
+	if _, err := d.Decode(); err == nil {
+		t.Fatalf("ParseOptions limits must apply to decoded values")
+	}
+}
@@ -0,0 +1,364 @@
+package easyjson
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Decode converts the value at path into target, which must be a non-nil pointer.
+// The conversion works directly on the value tree (no intermediate
+// serialization) and follows the rules of As: `json` tags, embedded
+// structs and encoding.TextUnmarshaler are honoured.
+func (j JSON) Decode(path string, target interface{}, delimiter ...string) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("easyjson: Decode target must be a non-nil pointer")
+	}
+	v, err := j.TryGetByPath(path, delimiter...)
+	if err != nil {
+		return err
+	}
+	return jvConvert(v.Value, rv.Elem(), path)
+}
+
+// FromStruct builds a JSON value from any Go value without an intermediate
+// serialization. Structs are mapped like encoding/json does: `json` tags
+// (including "-" and omitempty) are honoured, fields of embedded structs are
+// promoted, and json.Marshaler / encoding.TextMarshaler implementations are used.
+// Byte slices are stored in the default byte encoding, matching NewJSONBytes.
+func FromStruct(v interface{}) (JSON, error) {
+	res, err := jvFromValue(reflect.ValueOf(v))
+	if err != nil {
+		return NewJSONNull(), err
+	}
+	return NewJSON(res), nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonType          = reflect.TypeOf(JSON{})
+)
+
+func jvFromValue(rv reflect.Value) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if rv.Type() == jsonType {
+		return deepCopy(rv.Interface().(JSON).Value), nil
+	}
+	if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return nil, nil
+	}
+
+	if rv.Type().Implements(jsonMarshalerType) {
+		b, err := rv.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		var res interface{}
+		if err := json.Unmarshal(b, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	if rv.Type().Implements(textMarshalerType) {
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return jvFromValue(rv.Elem())
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Float32:
+		return float32(rv.Float()), nil
+	case reflect.Float64:
+		return rv.Float(), nil
+
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 && rv.Kind() == reflect.Slice {
+			return encodeBytes(rv.Bytes(), DefaultByteEncoding()), nil
+		}
+		arr := make([]interface{}, rv.Len())
+		for i := range arr {
+			e, err := jvFromValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = e
+		}
+		return arr, nil
+
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		obj := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k, err := jvMapKeyString(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			e, err := jvFromValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = e
+		}
+		return obj, nil
+
+	case reflect.Struct:
+		obj := map[string]interface{}{}
+		for _, f := range cachedStructFields(rv.Type()) {
+			fv, ok := fieldByIndex(rv, f.index)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			e, err := jvFromValue(fv)
+			if err != nil {
+				return nil, err
+			}
+			obj[f.name] = e
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("easyjson: unsupported type %s", rv.Type())
+}
+
+func jvMapKeyString(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("easyjson: unsupported map key type %s", k.Type())
+}
+
+// isEmptyValue reports whether v is empty in the omitempty sense of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// structField describes a (possibly promoted) struct field as seen by JSON.
+type structField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+}
+
+var structFieldCache sync.Map // map[reflect.Type][]structField
+
+func cachedStructFields(t reflect.Type) []structField {
+	if f, ok := structFieldCache.Load(t); ok {
+		return f.([]structField)
+	}
+	f, _ := structFieldCache.LoadOrStore(t, typeStructFields(t))
+	return f.([]structField)
+}
+
+// typeStructFields lists the JSON-visible fields of t, promoting fields of
+// embedded structs. As in encoding/json, shallower fields win over deeper
+// ones, and among fields at the same depth a tagged one wins; remaining
+// conflicts hide the name entirely.
+func typeStructFields(t reflect.Type) []structField {
+	type candidate struct {
+		structField
+		depth int
+	}
+	var all []candidate
+
+	var walk func(t reflect.Type, index []int, visited map[reflect.Type]bool)
+	walk = func(t reflect.Type, index []int, visited map[reflect.Type]bool) {
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		defer delete(visited, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			idx := append(index[:len(index):len(index)], i)
+
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+				walk(ft, idx, visited)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			all = append(all, candidate{
+				structField: structField{
+					name:      firstNonEmpty(name, f.Name),
+					index:     idx,
+					tagged:    name != "",
+					omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+				},
+				depth: len(idx),
+			})
+		}
+	}
+	walk(t, nil, map[reflect.Type]bool{})
+
+	byName := map[string][]candidate{}
+	var order []string
+	for _, c := range all {
+		if _, ok := byName[c.name]; !ok {
+			order = append(order, c.name)
+		}
+		byName[c.name] = append(byName[c.name], c)
+	}
+
+	var fields []structField
+	for _, name := range order {
+		cands := byName[name]
+		sort.SliceStable(cands, func(i, j int) bool { return cands[i].depth < cands[j].depth })
+		dominant := cands[:1]
+		for _, c := range cands[1:] {
+			if c.depth == dominant[0].depth {
+				dominant = append(dominant, c)
+			}
+		}
+		if len(dominant) > 1 {
+			var tagged []candidate
+			for _, c := range dominant {
+				if c.tagged {
+					tagged = append(tagged, c)
+				}
+			}
+			if len(tagged) != 1 {
+				continue
+			}
+			dominant = tagged
+		}
+		fields = append(fields, dominant[0].structField)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		for k := 0; k < len(fields[i].index) && k < len(fields[j].index); k++ {
+			if fields[i].index[k] != fields[j].index[k] {
+				return fields[i].index[k] < fields[j].index[k]
+			}
+		}
+		return len(fields[i].index) < len(fields[j].index)
+	})
+	return fields
+}
+
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but reports false instead
+// of panicking when it meets a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldByIndexAlloc is like reflect.Value.FieldByIndex but allocates nil embedded pointers.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// jvConvertStruct fills struct fields from an object, matching members by
+// JSON field name first and falling back to a case-insensitive match.
+func jvConvertStruct(obj map[string]interface{}, rv reflect.Value, path string) error {
+	for _, f := range cachedStructFields(rv.Type()) {
+		v, ok := obj[f.name]
+		if !ok {
+			for k, kv := range obj {
+				if strings.EqualFold(k, f.name) {
+					v, ok = kv, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		fv, ok := fieldByIndexAlloc(rv, f.index)
+		if !ok {
+			continue
+		}
+		if err := jvConvert(v, fv, convertChildPath(path, f.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package easyjson
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type structsBase struct {
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type StructsMeta struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type structsItem struct {
+	structsBase
+	*StructsMeta
+	Name     string   `json:"name"`
+	Comment  string   `json:"comment,omitempty"`
+	IP       net.IP   `json:"ip"`
+	Tags     []string `json:"tags"`
+	Ignored  string   `json:"-"`
+	Raw      []byte   `json:"raw,omitempty"`
+	Price    float64
+	internal int
+}
+
+func TestDecode_Struct(t *testing.T) {
+	j := mustJSONFromString(t, `{"data":{"item":{
+		"id": 7,
+		"created": "2024-05-01T10:00:00Z",
+		"labels": {"env":"prod"},
+		"name": "widget",
+		"ip": "10.0.0.1",
+		"tags": ["a","b"],
+		"Ignored": "x",
+		"raw": "cafe",
+		"price": 9.5
+	}}}`)
+
+	var it structsItem
+	if err := j.Decode("data.item", &it); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if it.ID != 7 || it.Name != "widget" || it.Price != 9.5 {
+		t.Fatalf("unexpected scalar fields: %+v", it)
+	}
+	if !it.Created.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("TextUnmarshaler not used for time: %v", it.Created)
+	}
+	if !it.IP.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Fatalf("TextUnmarshaler not used for net.IP: %v", it.IP)
+	}
+	if it.StructsMeta == nil || it.Labels["env"] != "prod" {
+		t.Fatalf("embedded pointer struct not filled: %+v", it.StructsMeta)
+	}
+	if it.Ignored != "" {
+		t.Fatalf("json:\"-\" field must be ignored")
+	}
+	if !reflect.DeepEqual(it.Raw, []byte{0xca, 0xfe}) {
+		t.Fatalf("raw bytes mismatch: %x", it.Raw)
+	}
+
+	if err := j.Decode("data.missing", &it); err == nil {
+		t.Fatalf("missing path must fail")
+	}
+	if err := j.Decode("data.item", it); err == nil {
+		t.Fatalf("non-pointer target must fail")
+	}
+}
+
+func TestFromStruct(t *testing.T) {
+	it := structsItem{
+		structsBase: structsBase{ID: 3, Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Name:        "gadget",
+		IP:          net.IPv4(192, 168, 0, 1),
+		Tags:        nil,
+		Ignored:     "x",
+		Price:       1.25,
+	}
+	j, err := FromStruct(&it)
+	if err != nil {
+		t.Fatalf("FromStruct failed: %v", err)
+	}
+
+	want := `{"Price":1.25,"created":"2024-01-02T03:04:05Z","id":3,"ip":"192.168.0.1","name":"gadget","tags":null}`
+	if got := j.ToString(); got != want {
+		t.Fatalf("\nwant: %s\ngot : %s", want, got)
+	}
+	if j.GetByPath("id").Value != int64(3) {
+		t.Fatalf("integers must be kept exact, got %T", j.GetByPath("id").Value)
+	}
+
+	it.StructsMeta = &StructsMeta{Labels: map[string]string{"k": "v"}}
+	it.Comment = "c"
+	it.Raw = []byte{1, 2}
+	j, _ = FromStruct(it)
+	if j.GetByPath("labels.k").AsStringDefault("") != "v" || j.GetByPath("comment").AsStringDefault("") != "c" {
+		t.Fatalf("promoted or non-empty fields missing: %s", j.ToString())
+	}
+	if b, ok := j.GetByPath("raw").AsBytes(); !ok || !reflect.DeepEqual(b, []byte{1, 2}) {
+		t.Fatalf("bytes must be hex encoded like NewJSONBytes: %s", j.ToString())
+	}
+}
+
+func TestFromStruct_Float32(t *testing.T) {
+	j, err := FromStruct(struct {
+		F float32 `json:"f"`
+	}{0.1})
+	if err != nil {
+		t.Fatalf("FromStruct failed: %v", err)
+	}
+	if got := j.ToString(); got != `{"f":0.1}` {
+		t.Fatalf("float32 must keep 32-bit precision, got %s", got)
+	}
+	if _, ok := j.GetByPath("f").Value.(float32); !ok {
+		t.Fatalf("expected a float32, got %T", j.GetByPath("f").Value)
+	}
+}
+
+func TestFromStruct_Roundtrip(t *testing.T) {
+	type inner struct {
+		A []int          `json:"a"`
+		M map[int]string `json:"m"`
+	}
+	type outer struct {
+		In  inner  `json:"in"`
+		Ptr *inner `json:"ptr"`
+		Any interface{}
+		Doc JSON `json:"doc"`
+	}
+	src := outer{
+		In:  inner{A: []int{1, 2}, M: map[int]string{1: "x"}},
+		Any: "str",
+		Doc: mustJSONFromString(t, `{"k":[true]}`),
+	}
+	j, err := FromStruct(src)
+	if err != nil {
+		t.Fatalf("FromStruct failed: %v", err)
+	}
+	if !j.GetByPath("doc.k.0").AsBoolDefault(false) || !j.GetByPath("ptr").IsNull() {
+		t.Fatalf("unexpected result: %s", j.ToString())
+	}
+	if j.GetByPath("in.m.1").AsStringDefault("") != "x" {
+		t.Fatalf("integer map keys must be stringified: %s", j.ToString())
+	}
+
+	var back outer
+	if err := j.Decode("", &back); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !reflect.DeepEqual(back.In.A, src.In.A) || back.Any != "str" {
+		t.Fatalf("roundtrip mismatch: %+v", back)
+	}
+
+	if _, err := FromStruct(make(chan int)); err == nil {
+		t.Fatalf("unsupported types must fail")
+	}
+}
@@ -0,0 +1,394 @@
+package easyjson
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// TypeError reports a JSON value that cannot be converted to the requested Go type.
+type TypeError struct {
+	Path   string
+	Value  interface{}
+	Type   reflect.Type
+	Reason string
+}
+
+func (e *TypeError) Error() string {
+	msg := fmt.Sprintf("easyjson: cannot convert %s at %q to %s", jvKindName(e.Value), e.Path, e.Type)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// As converts the JSON value to T.
+// Integers are converted exactly: fractional values and values that do not
+// fit into T are reported as errors instead of being rounded.
+// Supported targets are bool, string, all integer and float kinds,
+// time.Duration (from a duration string or a number of nanoseconds),
+// []byte (from a string in the default byte encoding, as produced by
+// NewJSONBytes), slices, arrays,
+// maps with string or integer keys, structs (honouring `json` tags and embedded structs),
+// encoding.TextUnmarshaler implementations, pointers and interface{}.
+func As[T any](j JSON) (T, error) {
+	var res T
+	err := jvConvert(j.Value, reflect.ValueOf(&res).Elem(), "")
+	return res, err
+}
+
+// GetAs returns the value at path converted to T. See As for the conversion rules.
+// A path that cannot be resolved is reported as a *PathError.
+func GetAs[T any](j JSON, path string, delimiter ...string) (T, error) {
+	var res T
+	v, err := j.TryGetByPath(path, delimiter...)
+	if err != nil {
+		return res, err
+	}
+	err = jvConvert(v.Value, reflect.ValueOf(&res).Elem(), path)
+	return res, err
+}
+
+// GetAsDefault returns the value at path converted to T, or defaultValue if
+// the path does not exist or the value cannot be converted.
+func GetAsDefault[T any](j JSON, path string, defaultValue T, delimiter ...string) T {
+	if res, err := GetAs[T](j, path, delimiter...); err == nil {
+		return res
+	}
+	return defaultValue
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	bytesType    = reflect.TypeOf([]byte(nil))
+	bigIntType   = reflect.TypeOf(big.Int{})
+)
+
+func convertChildPath(path, tok string) string {
+	if path == "" {
+		return tok
+	}
+	return path + "." + tok
+}
+
+// jvConvert stores the JSON value v into the settable rv.
+func jvConvert(v interface{}, rv reflect.Value, path string) error {
+	typeErr := func(reason string) error {
+		return &TypeError{Path: path, Value: v, Type: rv.Type(), Reason: reason}
+	}
+
+	if s, ok := v.(string); ok && rv.Kind() != reflect.Ptr && rv.CanAddr() {
+		if u, ok := rv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(s)); err != nil {
+				return typeErr(err.Error())
+			}
+			return nil
+		}
+	}
+
+	if rv.Type() == bigIntType {
+		b, ok := jvToBigInt(v)
+		if !ok {
+			return typeErr("not an integer")
+		}
+		rv.Addr().Interface().(*big.Int).Set(b)
+		return nil
+	}
+
+	if rv.Type() == durationType {
+		if s, ok := v.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return typeErr(err.Error())
+			}
+			rv.SetInt(int64(d))
+			return nil
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		if v == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		val := reflect.ValueOf(v)
+		if !val.Type().AssignableTo(rv.Type()) {
+			return typeErr("")
+		}
+		rv.Set(val)
+		return nil
+
+	case reflect.Ptr:
+		if v == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		elem := reflect.New(rv.Type().Elem())
+		if err := jvConvert(v, elem.Elem(), path); err != nil {
+			return err
+		}
+		rv.Set(elem)
+		return nil
+
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return typeErr("")
+		}
+		rv.SetBool(b)
+		return nil
+
+	case reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			return typeErr("")
+		}
+		rv.SetString(s)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, reason, ok := jvToInt64(v)
+		if !ok {
+			return typeErr(reason)
+		}
+		if rv.OverflowInt(n) {
+			return typeErr(fmt.Sprintf("value %d overflows %s", n, rv.Type()))
+		}
+		rv.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, reason, ok := jvToUint64(v)
+		if !ok {
+			return typeErr(reason)
+		}
+		if rv.OverflowUint(n) {
+			return typeErr(fmt.Sprintf("value %d overflows %s", n, rv.Type()))
+		}
+		rv.SetUint(n)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := numberToFloat64(v)
+		if !ok {
+			return typeErr("")
+		}
+		if rv.OverflowFloat(f) {
+			return typeErr(fmt.Sprintf("value %v overflows %s", f, rv.Type()))
+		}
+		rv.SetFloat(f)
+		return nil
+
+	case reflect.Slice:
+		if v == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.Type() == bytesType {
+			if s, ok := v.(string); ok {
+				b, _, err := decodeBytes(s, DefaultByteEncoding())
+				if err != nil {
+					return typeErr("not valid " + DefaultByteEncoding().String())
+				}
+				rv.SetBytes(b)
+				return nil
+			}
+		}
+		arr, ok := v.([]interface{})
+		if !ok {
+			return typeErr("")
+		}
+		out := reflect.MakeSlice(rv.Type(), len(arr), len(arr))
+		for i, e := range arr {
+			if err := jvConvert(e, out.Index(i), convertChildPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		rv.Set(out)
+		return nil
+
+	case reflect.Array:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return typeErr("")
+		}
+		if len(arr) != rv.Len() {
+			return typeErr(fmt.Sprintf("array length %d does not match %d", len(arr), rv.Len()))
+		}
+		for i, e := range arr {
+			if err := jvConvert(e, rv.Index(i), convertChildPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if v == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		obj, ok := jvObject(v)
+		if !ok {
+			return typeErr("")
+		}
+		out := reflect.MakeMapWithSize(rv.Type(), len(obj))
+		for k, e := range obj {
+			key := reflect.New(rv.Type().Key()).Elem()
+			if err := jvConvertMapKey(k, key); err != nil {
+				return typeErr(err.Error())
+			}
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := jvConvert(e, elem, convertChildPath(path, k)); err != nil {
+				return err
+			}
+			out.SetMapIndex(key, elem)
+		}
+		rv.Set(out)
+		return nil
+
+	case reflect.Struct:
+		obj, ok := jvObject(v)
+		if !ok {
+			return typeErr("")
+		}
+		return jvConvertStruct(obj, rv, path)
+	}
+
+	return typeErr("unsupported target type")
+}
+
+// jvConvertMapKey parses an object member name into a map key of string,
+// integer or encoding.TextUnmarshaler type.
+func jvConvertMapKey(k string, key reflect.Value) error {
+	if u, ok := key.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(k))
+	}
+	switch key.Kind() {
+	case reflect.String:
+		key.SetString(k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || key.OverflowInt(n) {
+			return fmt.Errorf("invalid map key %q for %s", k, key.Type())
+		}
+		key.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(k, 10, 64)
+		if err != nil || key.OverflowUint(n) {
+			return fmt.Errorf("invalid map key %q for %s", k, key.Type())
+		}
+		key.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported map key type %s", key.Type())
+	}
+	return nil
+}
+
+// jvToInt64 converts a numeric value to int64 without losing precision.
+func jvToInt64(v interface{}) (int64, string, bool) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), "", true
+	case int8:
+		return int64(x), "", true
+	case int16:
+		return int64(x), "", true
+	case int32:
+		return int64(x), "", true
+	case int64:
+		return x, "", true
+	case uint, uint8, uint16, uint32, uint64:
+		u, _, _ := jvToUint64(x)
+		if u > math.MaxInt64 {
+			return 0, fmt.Sprintf("value %d overflows int64", u), false
+		}
+		return int64(u), "", true
+	case json.Number:
+		if n, err := strconv.ParseInt(string(x), 10, 64); err == nil {
+			return n, "", true
+		}
+		b, ok := jvToBigInt(x)
+		if !ok {
+			return 0, fmt.Sprintf("%s is not an integer", x), false
+		}
+		return jvToInt64(b)
+	case *big.Int:
+		if x == nil || !x.IsInt64() {
+			return 0, fmt.Sprintf("value %v overflows int64", x), false
+		}
+		return x.Int64(), "", true
+	case float32:
+		return jvToInt64(float64(x))
+	case float64:
+		if x != math.Trunc(x) {
+			return 0, fmt.Sprintf("%v is not an integer", x), false
+		}
+		if x < math.MinInt64 || x >= math.MaxInt64 {
+			return 0, fmt.Sprintf("value %v overflows int64", x), false
+		}
+		return int64(x), "", true
+	}
+	return 0, "", false
+}
+
+// jvToUint64 converts a numeric value to uint64 without losing precision.
+func jvToUint64(v interface{}) (uint64, string, bool) {
+	switch x := v.(type) {
+	case uint:
+		return uint64(x), "", true
+	case uint8:
+		return uint64(x), "", true
+	case uint16:
+		return uint64(x), "", true
+	case uint32:
+		return uint64(x), "", true
+	case uint64:
+		return x, "", true
+	case int, int8, int16, int32, int64:
+		n, _, _ := jvToInt64(x)
+		if n < 0 {
+			return 0, fmt.Sprintf("negative value %d", n), false
+		}
+		return uint64(n), "", true
+	case json.Number:
+		if n, err := strconv.ParseUint(string(x), 10, 64); err == nil {
+			return n, "", true
+		}
+		b, ok := jvToBigInt(x)
+		if !ok {
+			return 0, fmt.Sprintf("%s is not an integer", x), false
+		}
+		return jvToUint64(b)
+	case *big.Int:
+		if x == nil {
+			return 0, "", false
+		}
+		if x.Sign() < 0 {
+			return 0, fmt.Sprintf("negative value %v", x), false
+		}
+		if !x.IsUint64() {
+			return 0, fmt.Sprintf("value %v overflows uint64", x), false
+		}
+		return x.Uint64(), "", true
+	case float32:
+		return jvToUint64(float64(x))
+	case float64:
+		if x != math.Trunc(x) {
+			return 0, fmt.Sprintf("%v is not an integer", x), false
+		}
+		if x < 0 {
+			return 0, fmt.Sprintf("negative value %v", x), false
+		}
+		if x >= math.MaxUint64 {
+			return 0, fmt.Sprintf("value %v overflows uint64", x), false
+		}
+		return uint64(x), "", true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,136 @@
+package easyjson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetAs_Scalars(t *testing.T) {
+	j := mustJSONFromString(t, `{"i":42,"f":1.5,"s":"str","b":true,"big":9007199254740993,"neg":-1,"d":"1m30s","dn":2000000000}`)
+
+	if v, err := GetAs[int](j, "i"); err != nil || v != 42 {
+		t.Fatalf("int: %v %v", v, err)
+	}
+	if v, err := GetAs[int64](j, "neg"); err != nil || v != -1 {
+		t.Fatalf("int64: %v %v", v, err)
+	}
+	if v, err := GetAs[uint64](j, "i"); err != nil || v != 42 {
+		t.Fatalf("uint64: %v %v", v, err)
+	}
+	if v, err := GetAs[float32](j, "f"); err != nil || v != 1.5 {
+		t.Fatalf("float32: %v %v", v, err)
+	}
+	if v, err := GetAs[string](j, "s"); err != nil || v != "str" {
+		t.Fatalf("string: %v %v", v, err)
+	}
+	if v, err := GetAs[bool](j, "b"); err != nil || !v {
+		t.Fatalf("bool: %v %v", v, err)
+	}
+	if v, err := GetAs[time.Duration](j, "d"); err != nil || v != 90*time.Second {
+		t.Fatalf("duration string: %v %v", v, err)
+	}
+	if v, err := GetAs[time.Duration](j, "dn"); err != nil || v != 2*time.Second {
+		t.Fatalf("duration number: %v %v", v, err)
+	}
+}
+
+func TestGetAs_PreciseIntegers(t *testing.T) {
+	j := NewJSON(map[string]interface{}{
+		"frac":   1.5,
+		"big":    300,
+		"neg":    -5,
+		"huge":   1e20,
+		"native": int64(1<<62 + 1),
+		"max":    uint64(1<<64 - 1),
+	})
+
+	var terr *TypeError
+	if _, err := GetAs[int](j, "frac"); !errors.As(err, &terr) {
+		t.Fatalf("fractional value must fail, got %v", err)
+	}
+	if _, err := GetAs[uint8](j, "big"); !errors.As(err, &terr) {
+		t.Fatalf("300 must overflow uint8, got %v", err)
+	}
+	if _, err := GetAs[uint32](j, "neg"); err == nil {
+		t.Fatalf("negative value must not convert to uint32")
+	}
+	if _, err := GetAs[int64](j, "huge"); err == nil {
+		t.Fatalf("1e20 must overflow int64")
+	}
+	if v, err := GetAs[int64](j, "native"); err != nil || v != 1<<62+1 {
+		t.Fatalf("native int64 must be exact: %v %v", v, err)
+	}
+	if v, err := GetAs[uint64](j, "max"); err != nil || v != 1<<64-1 {
+		t.Fatalf("native uint64 must be exact: %v %v", v, err)
+	}
+	if _, err := GetAs[int64](j, "max"); err == nil {
+		t.Fatalf("max uint64 must overflow int64")
+	}
+}
+
+func TestGetAs_Composite(t *testing.T) {
+	j := mustJSONFromString(t, `{
+		"list":[1,2,3],
+		"names":{"a":"x","b":"y"},
+		"nested":[[1],[2,3]],
+		"user":{"name":"John","Age":30,"tags":["a"],"skip":"no"},
+		"bytes":"deadbeef"
+	}`)
+
+	if v, err := GetAs[[]int](j, "list"); err != nil || !reflect.DeepEqual(v, []int{1, 2, 3}) {
+		t.Fatalf("[]int: %v %v", v, err)
+	}
+	if v, err := GetAs[map[string]string](j, "names"); err != nil || v["b"] != "y" {
+		t.Fatalf("map[string]string: %v %v", v, err)
+	}
+	if v, err := GetAs[[][]uint16](j, "nested"); err != nil || !reflect.DeepEqual(v, [][]uint16{{1}, {2, 3}}) {
+		t.Fatalf("[][]uint16: %v %v", v, err)
+	}
+	if v, err := GetAs[[]byte](j, "bytes"); err != nil || !reflect.DeepEqual(v, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Fatalf("[]byte: %v %v", v, err)
+	}
+
+	type user struct {
+		Name string
+		Age  int      `json:"age"`
+		Tags []string `json:"tags,omitempty"`
+		Skip string   `json:"-"`
+	}
+	u, err := GetAs[user](j, "user")
+	if err != nil {
+		t.Fatalf("struct: %v", err)
+	}
+	if u.Name != "John" || u.Age != 30 || len(u.Tags) != 1 || u.Skip != "" {
+		t.Fatalf("unexpected struct: %+v", u)
+	}
+
+	_, err = GetAs[[]string](j, "list")
+	var terr *TypeError
+	if !errors.As(err, &terr) || terr.Path != "list.0" {
+		t.Fatalf("expected TypeError at list.0, got %v", err)
+	}
+}
+
+func TestGetAs_MissingAndDefault(t *testing.T) {
+	j := mustJSONFromString(t, `{"a":{"b":"x"},"n":null}`)
+	if _, err := GetAs[string](j, "a.c"); err == nil {
+		t.Fatalf("missing path must fail")
+	}
+	if v := GetAsDefault(j, "a.c", "def"); v != "def" {
+		t.Fatalf("expected default, got %q", v)
+	}
+	if v := GetAsDefault(j, "a.b", 7); v != 7 {
+		t.Fatalf("expected default on type mismatch, got %v", v)
+	}
+	if v := GetAsDefault(j, "a.b", "def"); v != "x" {
+		t.Fatalf("expected x, got %q", v)
+	}
+	if v, err := GetAs[*string](j, "n"); err != nil || v != nil {
+		t.Fatalf("null must convert to nil pointer: %v %v", v, err)
+	}
+	if v, err := As[map[string]interface{}](j); err != nil || len(v) != 2 {
+		t.Fatalf("As on root failed: %v %v", v, err)
+	}
+}
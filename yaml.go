@@ -0,0 +1,342 @@
+package easyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLOptions configures JSONFromYAML and JSONFromYAMLStream.
+type YAMLOptions struct {
+	// PreserveKeyOrder decodes mappings into *OrderedObject values that keep
+	// the key order of the document.
+	PreserveKeyOrder bool
+	// Numbers selects the Go representation of numbers; the default is
+	// float64, as for JSON input.
+	Numbers NumberMode
+}
+
+// yamlExpansionLimit bounds the number of values aliases may expand a
+// document to, per byte of input, to defuse "billion laughs" documents.
+const yamlExpansionLimit = 100
+
+// JSONFromYAML decodes a YAML document into a JSON value tree:
+//   - mappings become objects and sequences arrays;
+//   - aliases are resolved to copies of their anchored values and merge
+//     keys (<<) are applied;
+//   - mapping keys that are not strings are stringified (null, true, 42,
+//     or the compact JSON text of a sequence or mapping key);
+//   - !!binary values become strings in the default byte encoding, as
+//     produced by NewJSONBytes, and timestamps RFC 3339 strings.
+//
+// An empty document decodes to null. Streams of several documents are
+// rejected; use JSONFromYAMLStream for them.
+func JSONFromYAML(b []byte, opts ...YAMLOptions) (JSON, error) {
+	docs, err := JSONFromYAMLStream(b, opts...)
+	if err != nil {
+		return NewJSONNull(), err
+	}
+	switch len(docs) {
+	case 0:
+		return NewJSONNull(), nil
+	case 1:
+		return docs[0], nil
+	}
+	return NewJSONNull(), fmt.Errorf("easyjson: YAML stream has %d documents", len(docs))
+}
+
+// JSONFromYAMLStream decodes every document of a YAML stream, see JSONFromYAML.
+func JSONFromYAMLStream(b []byte, opts ...YAMLOptions) ([]JSON, error) {
+	d := &yamlDecoder{budget: yamlExpansionLimit * (len(b) + 1), active: map[*yaml.Node]bool{}}
+	if len(opts) > 0 {
+		d.opts = opts[0]
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	var docs []JSON
+	for {
+		var n yaml.Node
+		if err := dec.Decode(&n); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, fmt.Errorf("easyjson: %w", err)
+		}
+		v, err := d.value(&n)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, NewJSON(v))
+	}
+}
+
+// JSONFromYAMLString is like JSONFromYAML for string input.
+func JSONFromYAMLString(s string, opts ...YAMLOptions) (JSON, error) {
+	return JSONFromYAML([]byte(s), opts...)
+}
+
+type yamlDecoder struct {
+	opts   YAMLOptions
+	budget int
+	active map[*yaml.Node]bool // aliased nodes being expanded, to detect cycles
+}
+
+func (d *yamlDecoder) value(n *yaml.Node) (interface{}, error) {
+	if d.budget--; d.budget < 0 {
+		return nil, errors.New("easyjson: YAML aliases expand to too many values")
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return d.value(n.Content[0])
+	case yaml.AliasNode:
+		if d.active[n.Alias] {
+			return nil, fmt.Errorf("easyjson: YAML line %d: alias *%s refers to itself", n.Line, n.Value)
+		}
+		d.active[n.Alias] = true
+		defer delete(d.active, n.Alias)
+		return d.value(n.Alias)
+	case yaml.SequenceNode:
+		arr := make([]interface{}, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := d.value(c)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case yaml.MappingNode:
+		return d.mapping(n)
+	}
+	return d.scalar(n)
+}
+
+func (d *yamlDecoder) mapping(n *yaml.Node) (interface{}, error) {
+	var obj interface{} = map[string]interface{}{}
+	if d.opts.PreserveKeyOrder {
+		obj = NewOrderedObject()
+	}
+	members, _ := jvObject(obj)
+
+	// Explicit keys take precedence over merged ones wherever they appear.
+	explicit := map[string]bool{}
+	keys := make([]string, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].ShortTag() == "!!merge" {
+			continue
+		}
+		k, err := d.key(n.Content[i])
+		if err != nil {
+			return nil, err
+		}
+		keys[i/2] = k
+		explicit[k] = true
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].ShortTag() != "!!merge" {
+			v, err := d.value(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			jvObjectSet(obj, keys[i/2], v)
+			continue
+		}
+		v, err := d.value(n.Content[i+1])
+		if err != nil {
+			return nil, err
+		}
+		sources := []interface{}{v}
+		if arr, ok := v.([]interface{}); ok {
+			sources = arr
+		}
+		for _, src := range sources {
+			merged, ok := jvObject(src)
+			if !ok {
+				return nil, fmt.Errorf("easyjson: YAML line %d: merge key value is not a mapping", n.Content[i].Line)
+			}
+			for _, k := range jvObjectKeys(src) {
+				if _, set := members[k]; !set && !explicit[k] {
+					jvObjectSet(obj, k, merged[k])
+				}
+			}
+		}
+	}
+	return obj, nil
+}
+
+// key returns the member name for a mapping key node.
+func (d *yamlDecoder) key(n *yaml.Node) (string, error) {
+	v, err := d.value(n)
+	if err != nil {
+		return "", err
+	}
+	k, err := jvKeyString(v)
+	if err != nil {
+		return "", fmt.Errorf("easyjson: YAML line %d: unsupported mapping key: %w", n.Line, err)
+	}
+	return k, nil
+}
+
+// jvKeyString stringifies a decoded non-string map key of a binary or YAML
+// document: null, true, 42, or the compact JSON text of a composite key.
+func jvKeyString(v interface{}) (string, error) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case float64:
+		return string(appendShortestFloat(nil, x, 64)), nil
+	}
+	if s, ok := NewJSON(v).AsDecimalString(); ok {
+		return s, nil
+	}
+	b, err := NewJSON(v).ToBytesWith(SerializeOptions{DisableHTMLEscape: true})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *yamlDecoder) scalar(n *yaml.Node) (interface{}, error) {
+	switch n.ShortTag() {
+	case "!!binary":
+		var s string // the decoded bytes
+		if err := n.Decode(&s); err != nil {
+			return nil, fmt.Errorf("easyjson: %w", err)
+		}
+		return encodeBytes([]byte(s), DefaultByteEncoding()), nil
+	case "!!int":
+		if b, ok := new(big.Int).SetString(n.Value, 0); ok {
+			return parseNumber(json.Number(b.String()), d.opts.Numbers)
+		}
+	}
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return nil, fmt.Errorf("easyjson: %w", err)
+	}
+	switch x := v.(type) {
+	case int:
+		return parseNumber(json.Number(strconv.Itoa(x)), d.opts.Numbers)
+	case uint64:
+		return parseNumber(json.Number(strconv.FormatUint(x, 10)), d.opts.Numbers)
+	case float64:
+		return floatForMode(x, d.opts.Numbers), nil
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	}
+	return v, nil
+}
+
+// ToYAML serializes the JSON value as a YAML document. Object members are
+// written in key order (insertion order for ordered objects, sorted
+// otherwise) and strings that YAML would read as another type are quoted,
+// so JSONFromYAML restores the same tree.
+func (j JSON) ToYAML() ([]byte, error) {
+	return ToYAMLStream(j)
+}
+
+// ToYAMLStream serializes the values as a stream of YAML documents separated
+// by "---".
+func ToYAMLStream(docs ...JSON) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		n, err := yamlNode(doc.Value)
+		if err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(n); err != nil {
+			return nil, fmt.Errorf("easyjson: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("easyjson: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func yamlScalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+func yamlNode(v interface{}) (*yaml.Node, error) {
+	switch x := v.(type) {
+	case nil:
+		return yamlScalar("!!null", "null"), nil
+	case bool:
+		return yamlScalar("!!bool", strconv.FormatBool(x)), nil
+	case string:
+		return yamlScalar("!!str", x), nil
+	case float64:
+		return yamlFloat(x, 64), nil
+	case float32:
+		return yamlFloat(float64(x), 32), nil
+	case json.Number:
+		return yamlScalar("", x.String()), nil
+	case []interface{}:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, e := range x {
+			c, err := yamlNode(e)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, c)
+		}
+		return n, nil
+	case map[string]interface{}, *OrderedObject:
+		obj, _ := jvObject(x)
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range jvObjectKeys(x) {
+			c, err := yamlNode(obj[k])
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, yamlScalar("!!str", k), c)
+		}
+		return n, nil
+	}
+	if s, ok := NewJSON(v).AsDecimalString(); ok {
+		return yamlScalar("", s), nil
+	}
+
+	// Other Go types are rendered by encoding/json first, as in ToBytesWith.
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return yamlNode(generic)
+}
+
+func yamlFloat(f float64, bits int) *yaml.Node {
+	switch {
+	case math.IsNaN(f):
+		return yamlScalar("!!float", ".nan")
+	case math.IsInf(f, 1):
+		return yamlScalar("!!float", ".inf")
+	case math.IsInf(f, -1):
+		return yamlScalar("!!float", "-.inf")
+	}
+	// Untagged, so that integral values are written as integers.
+	return yamlScalar("", string(appendShortestFloat(nil, f, bits)))
+}
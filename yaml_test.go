@@ -0,0 +1,130 @@
+package easyjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONFromYAML(t *testing.T) {
+	src := `
+defaults: &defaults
+  adapter: postgres
+  port: 5432
+development:
+  <<: *defaults
+  port: 5433
+  hosts: [a, b]
+1: one
+true: yes-string
+null: nothing
+[x, y]: pair
+bin: !!binary aGk=
+quoted: "007"
+`
+	j, err := JSONFromYAMLString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := j.GetByPath("development.adapter").Value; got != "postgres" {
+		t.Errorf("merged key: %#v", got)
+	}
+	if got := j.GetByPath("development.port").Value; got != float64(5433) {
+		t.Errorf("explicit key must win over merged one: %#v", got)
+	}
+	if got := j.GetByPath("development.hosts.1").Value; got != "b" {
+		t.Errorf("sequence: %#v", got)
+	}
+	for key, want := range map[string]interface{}{
+		"1": "one", "true": "yes-string", "null": "nothing", `["x","y"]`: "pair", "bin": "6869", "quoted": "007",
+	} {
+		if got := j.GetByPath(key, "|").Value; got != want {
+			t.Errorf("%q: got %#v, want %#v", key, got, want)
+		}
+	}
+	if b, ok := j.GetByPath("bin").AsBytes(); !ok || string(b) != "hi" {
+		t.Errorf("binary must follow the NewJSONBytes convention: %q", b)
+	}
+
+	j.SetByPath("development.port", NewJSON(6000))
+	if got := j.GetByPath("defaults.port").Value; got != float64(5432) {
+		t.Errorf("aliases must be resolved to copies: %#v", got)
+	}
+
+	ordered, err := JSONFromYAMLString("b: 1\na: {d: 2, c: 3}\n", YAMLOptions{PreserveKeyOrder: true, Numbers: NumberTyped})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := ordered.ObjectKeys(); !reflect.DeepEqual(keys, []string{"b", "a"}) {
+		t.Errorf("key order: %v", keys)
+	}
+	if got := ordered.GetByPath("a.c").Value; got != int64(3) {
+		t.Errorf("typed number: %#v", got)
+	}
+
+	for _, in := range []string{"a: [1", "a: &x [*x]", "- !!binary '%%'", "a: 1\n---\nb: 2"} {
+		if _, err := JSONFromYAMLString(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+
+	laughs := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+	for c := 'b'; c <= 'j'; c++ {
+		prev := string(c - 1)
+		laughs += string(c) + ": &" + string(c) + " [" + strings.Repeat("*"+prev+", ", 9) + "*" + prev + "]\n"
+	}
+	if _, err := JSONFromYAMLString(laughs); err == nil {
+		t.Error("expected exponential alias expansion to be rejected")
+	}
+}
+
+func TestJSONFromYAMLStream(t *testing.T) {
+	docs, err := JSONFromYAMLStream([]byte("a: 1\n---\n- x\n---\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 || docs[0].GetByPath("a").Value != float64(1) || docs[1].GetByPath("0").Value != "x" || !docs[2].IsNull() {
+		t.Fatalf("unexpected documents %#v", docs)
+	}
+	if j, err := JSONFromYAMLString(""); err != nil || !j.IsNull() {
+		t.Fatalf("empty input: %v %v", j, err)
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	j, _ := JSONFromString(`{"name":"svc","port":80,"ratio":0.5,"tags":["a","true","1"],"nested":{"x":null,"y":false},"empty":[]}`)
+	out, err := j.ToYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `empty: []
+name: svc
+nested:
+  x: null
+  y: false
+port: 80
+ratio: 0.5
+tags:
+  - a
+  - "true"
+  - "1"
+`
+	if string(out) != want {
+		t.Fatalf("got:\n%s", out)
+	}
+	back, err := JSONFromYAML(out)
+	if err != nil || !back.Equals(j) {
+		t.Fatalf("round trip failed: %v %v", back, err)
+	}
+
+	o := NewJSONOrderedObject()
+	o.SetByPath("z", NewJSON(1))
+	o.SetByPath("a", NewJSON(2))
+	stream, err := ToYAMLStream(o, NewJSON("doc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stream) != "z: 1\na: 2\n---\ndoc\n" {
+		t.Fatalf("stream:\n%s", stream)
+	}
+}